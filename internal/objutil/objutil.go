@@ -0,0 +1,225 @@
+// Package objutil 在本地模拟 S3 语义，供 s3mem、s3fs 等非 S3 实现共用
+package objutil
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+
+	"github.com/ix64/s3-go/s3"
+	"github.com/ix64/s3-go/s3common"
+)
+
+const (
+	listDelimiter      = "/"
+	listDefaultMaxKeys = 1000
+
+	// listPrefixSkipUntil 大于任何合法的 UTF-8 后缀，用于跳过整个 common prefix
+	listPrefixSkipUntil = "\U0010FFFF"
+)
+
+// NewError 构造与 S3 服务端一致的错误，以便 errors.Is / errors.As 的行为与 s3.Client 相同
+func NewError(code string, statusCode int, objectName string) error {
+	return s3common.ConvertError(minio.ErrorResponse{
+		Code:       code,
+		Message:    http.StatusText(statusCode),
+		Key:        objectName,
+		StatusCode: statusCode,
+	})
+}
+
+// NotFound 对象不存在
+func NotFound(objectName string) error {
+	return NewError("NoSuchKey", http.StatusNotFound, objectName)
+}
+
+// ComposeObjectName 与 s3.Client 相同，将 Config.Prefix 与路径拼接为 object name
+func ComposeObjectName(prefix string, remotePath string) string {
+	// s3 object name should not start with "/"
+	return strings.TrimPrefix(path.Join(prefix, remotePath), "/")
+}
+
+// ComposeListPrefix 与 s3.Client 相同，空前缀或以 "/" 结尾的前缀视为目录，需要保留结尾的 "/"
+func ComposeListPrefix(prefix string, listPrefix string) string {
+	name := ComposeObjectName(prefix, listPrefix)
+	if name != "" && (listPrefix == "" || strings.HasSuffix(listPrefix, "/")) {
+		name += "/"
+	}
+	return name
+}
+
+// CheckDeletePrefix 与 s3.Client 相同，前缀为空时拒绝删除全部文件
+func CheckDeletePrefix(listPrefix string) error {
+	if listPrefix == "" {
+		return fmt.Errorf("%w: prefix is empty, refuse to delete the whole bucket", s3common.ErrInvalidArgument)
+	}
+	return nil
+}
+
+// CheckListPrefix 与 s3.Client 相同，拒绝经 path.Join 清理后跳出 Config.Prefix 的前缀，如 "../other"
+func CheckListPrefix(prefix string, listPrefix string) error {
+	root := ComposeObjectName(prefix, "")
+	name := ComposeObjectName(prefix, listPrefix)
+
+	if root == "" {
+		if name != ".." && !strings.HasPrefix(name, "../") {
+			return nil
+		}
+	} else if name == root || strings.HasPrefix(name, root+"/") {
+		return nil
+	}
+
+	return fmt.Errorf("%w: prefix %q is outside of Config.Prefix", s3common.ErrInvalidArgument, listPrefix)
+}
+
+// TrimObjectName 去除 object name 中的 Config.Prefix，还原为逻辑路径
+func TrimObjectName(prefix string, objectName string) string {
+	prefix = ComposeObjectName(prefix, "")
+	if prefix == "" {
+		return objectName
+	}
+	return strings.TrimPrefix(objectName, prefix+"/")
+}
+
+// TrimETag 去除 ETag 两侧的引号
+func TrimETag(etag string) string {
+	return strings.Trim(etag, "\"")
+}
+
+// CheckConditions 判断条件请求，判断顺序参照 RFC 7232 Section 6
+func CheckConditions(etag string, lastModified time.Time, opts *s3.DownloadOptions, objectName string) error {
+	// HTTP dates have second precision
+	lastModified = lastModified.Truncate(time.Second)
+
+	if opts.IfMatch != "" && TrimETag(opts.IfMatch) != etag {
+		return NewError("PreconditionFailed", http.StatusPreconditionFailed, objectName)
+	}
+	if opts.IfMatch == "" && !opts.IfUnmodifiedSince.IsZero() && lastModified.After(opts.IfUnmodifiedSince) {
+		return NewError("PreconditionFailed", http.StatusPreconditionFailed, objectName)
+	}
+
+	if opts.IfNoneMatch != "" && TrimETag(opts.IfNoneMatch) == etag {
+		return NewError("NotModified", http.StatusNotModified, objectName)
+	}
+	if opts.IfNoneMatch == "" && !opts.IfModifiedSince.IsZero() && !lastModified.After(opts.IfModifiedSince) {
+		return NewError("NotModified", http.StatusNotModified, objectName)
+	}
+
+	return nil
+}
+
+// ResolveRange 返回读取范围 [start, end) 及是否为范围请求
+func ResolveRange(opts *s3.DownloadOptions, total int64, objectName string) (start int64, end int64, partial bool, err error) {
+	switch {
+	case opts.Offset < 0 || opts.Length < 0 || opts.SuffixLength < 0:
+		return 0, 0, false, fmt.Errorf("%w: offset, length and suffix length can not be negative", s3common.ErrInvalidArgument)
+	case opts.SuffixLength > 0:
+		if opts.Offset != 0 || opts.Length != 0 {
+			return 0, 0, false, fmt.Errorf("%w: suffix length can not be used with offset and length", s3common.ErrInvalidArgument)
+		}
+		if total == 0 {
+			return 0, 0, false, NewError("InvalidRange", http.StatusRequestedRangeNotSatisfiable, objectName)
+		}
+		return max(total-opts.SuffixLength, 0), total, true, nil
+	case opts.Offset > 0 || opts.Length > 0:
+		if opts.Offset >= total {
+			return 0, 0, false, NewError("InvalidRange", http.StatusRequestedRangeNotSatisfiable, objectName)
+		}
+		end := total
+		if opts.Length > 0 {
+			end = min(opts.Offset+opts.Length, total)
+		}
+		return opts.Offset, end, true, nil
+	default:
+		return 0, total, false, nil
+	}
+}
+
+// ContentRange 生成 "Content-Range" 响应头
+func ContentRange(start, end, total int64) string {
+	return fmt.Sprintf("bytes %d-%d/%d", start, end-1, total)
+}
+
+// ListPage 分页列举的结果，Objects 与 Prefixes 均为包含 Config.Prefix 的 object name
+type ListPage struct {
+	Objects  []string
+	Prefixes []string
+
+	IsTruncated           bool
+	NextContinuationToken string
+}
+
+// List 按 ListObjectsV2 的语义对 names 分页
+//
+// names 无需有序，listPrefix 为 s3 列举前缀，startAfter 为包含 Config.Prefix 的 object name
+func List(names []string, listPrefix string, startAfter string, opts *s3.ListOptions) (*ListPage, error) {
+	maxKeys := opts.MaxKeys
+	if maxKeys <= 0 {
+		maxKeys = listDefaultMaxKeys
+	}
+
+	marker := startAfter
+	if opts.ContinuationToken != "" {
+		token, err := base64.RawURLEncoding.DecodeString(opts.ContinuationToken)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid continuation token", s3common.ErrInvalidArgument)
+		}
+		marker = max(marker, string(token))
+	}
+
+	names = slices.Clone(names)
+	slices.Sort(names)
+
+	ret := &ListPage{
+		Objects:  []string{},
+		Prefixes: []string{},
+	}
+
+	last, lastIsPrefix := "", false
+	for _, name := range names {
+		if !strings.HasPrefix(name, listPrefix) || name <= marker {
+			continue
+		}
+
+		commonPrefix := ""
+		if !opts.Recursive {
+			if i := strings.Index(name[len(listPrefix):], listDelimiter); i >= 0 {
+				commonPrefix = name[:len(listPrefix)+i+1]
+			}
+		}
+
+		// keys under the same common prefix are grouped into one entry
+		if lastIsPrefix && commonPrefix == last {
+			continue
+		}
+
+		if len(ret.Objects)+len(ret.Prefixes) >= maxKeys {
+			ret.IsTruncated = true
+			break
+		}
+
+		if commonPrefix != "" {
+			ret.Prefixes = append(ret.Prefixes, commonPrefix)
+			last, lastIsPrefix = commonPrefix, true
+		} else {
+			ret.Objects = append(ret.Objects, name)
+			last, lastIsPrefix = name, false
+		}
+	}
+
+	if ret.IsTruncated {
+		token := last
+		if lastIsPrefix {
+			token += listPrefixSkipUntil
+		}
+		ret.NextContinuationToken = base64.RawURLEncoding.EncodeToString([]byte(token))
+	}
+
+	return ret, nil
+}
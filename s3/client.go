@@ -2,7 +2,6 @@ package s3
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"net/url"
 	"path"
@@ -11,6 +10,7 @@ import (
 
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
 
 	"github.com/ix64/s3-go/s3common"
 	"github.com/ix64/s3-go/s3down"
@@ -23,12 +23,21 @@ type Client struct {
 	cfg    *Config
 	prefix string
 
+	// bucket 为传给 SDK 的 bucket 名称，cname 时为自定义域名拆分出的虚拟主机前缀
+	bucket string
+
 	c *minio.Client
 
 	endpoint *url.URL
 
+	// creds 与 S3 上传、下载生成器共享，临时凭证只需刷新一次
+	creds *credentials.Credentials
+
 	region string
 
+	// sse 默认的服务端加密方式，nil 时使用 bucket 的默认配置
+	sse encrypt.ServerSide
+
 	upload   s3up.Generator
 	download s3down.Generator
 }
@@ -61,9 +70,12 @@ func (c *Client) init(ctx context.Context) (err error) {
 
 	c.endpoint, err = url.Parse(c.cfg.Endpoint)
 	if err != nil {
-		return err
+		return &s3common.ConfigError{Field: "endpoint", Reason: "is invalid: " + err.Error()}
 	}
 
+	host := c.endpoint.Host
+	c.bucket = c.cfg.Bucket
+
 	var bucketLookup minio.BucketLookupType
 	switch c.cfg.BucketLookup {
 	case s3common.BucketLookupDNS:
@@ -71,13 +83,28 @@ func (c *Client) init(ctx context.Context) (err error) {
 	case s3common.BucketLookupPath:
 		bucketLookup = minio.BucketLookupPath
 	case s3common.BucketLookupCNAME:
-		return errors.New("custom domain by CNAME is not supported for S3 UploadGenerator")
+		// bucket is implicit, access custom domain as virtual host
+		c.bucket, host, err = s3common.SplitCNAMEHost(host)
+		if err != nil {
+			return err
+		}
+		bucketLookup = minio.BucketLookupDNS
 	default:
-		return fmt.Errorf("unknown bucket lookup type: %s", c.cfg.BucketLookup)
+		return &s3common.ConfigError{Field: "bucket_lookup", Reason: "is unknown: " + string(c.cfg.BucketLookup)}
+	}
+
+	c.sse, err = s3common.NewServerSide(c.cfg.Encryption)
+	if err != nil {
+		return err
+	}
+
+	c.creds, err = s3common.ResolveCredentials(nil, c.cfg.Credentials, c.cfg.AccessKey, c.cfg.SecretKey)
+	if err != nil {
+		return err
 	}
 
-	c.c, err = minio.New(c.endpoint.Host, &minio.Options{
-		Creds:        credentials.NewStaticV4(c.cfg.AccessKey, c.cfg.SecretKey, ""),
+	c.c, err = minio.New(host, &minio.Options{
+		Creds:        c.creds,
 		Secure:       c.endpoint.Scheme == "https",
 		BucketLookup: bucketLookup,
 	})
@@ -87,9 +114,9 @@ func (c *Client) init(ctx context.Context) (err error) {
 
 	c.region = c.cfg.Region
 	if c.region == "" {
-		c.region, err = c.c.GetBucketLocation(ctx, c.cfg.Bucket)
+		c.region, err = c.c.GetBucketLocation(ctx, c.bucket)
 		if err != nil {
-			return fmt.Errorf("failed to get bucket location: %w", err)
+			return fmt.Errorf("failed to get bucket location: %w", s3common.ConvertError(err))
 		}
 	}
 
@@ -3,19 +3,27 @@ package s3_test
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/minio/minio-go/v7"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/ix64/s3-go/s3"
+	"github.com/ix64/s3-go/s3common"
 	"github.com/ix64/s3-go/s3down"
 	"github.com/ix64/s3-go/s3up"
 )
@@ -99,10 +107,963 @@ func TestClient_ObjectOperation(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	t.Run("StatNotFound", func(t *testing.T) {
+		_, err := c.Stat(context.Background(), remotePath)
+		assert.ErrorIs(t, err, s3.ErrNotFound)
+	})
+
+	t.Run("FileContentSame", func(t *testing.T) {
+		assertFileContentSame(t, origPath, downloadPath)
+	})
+
+}
+
+func TestClient_MetadataTags(t *testing.T) {
+	c, _, err := initE2EClient(t)
+	if err != nil {
+		t.Errorf("failed to init e2e client: %v", err)
+		return
+	}
+
+	ctx := context.Background()
+	remotePath := "__e2e_test__/metadata-tags/uploaded.txt"
+	content := []byte("hello, metadata")
+
+	err = c.UploadWithOptions(ctx, remotePath, bytes.NewReader(content), int64(len(content)), &s3.PutOptions{
+		ContentType:  "text/plain",
+		UserMetadata: map[string]string{"Owner": "e2e"},
+		Tags:         map[string]string{"env": "test"},
+		CacheControl: "max-age=60",
+	})
+	assert.NoError(t, err)
+
+	t.Cleanup(func() {
+		err := c.Delete(ctx, remotePath)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Stat", func(t *testing.T) {
+		stat, err := c.Stat(ctx, remotePath)
+		assert.NoError(t, err)
+		assert.Equal(t, "text/plain", stat.ContentType)
+		assert.Equal(t, "e2e", stat.UserMetadata["Owner"])
+		assert.Equal(t, "max-age=60", stat.Metadata.Get("Cache-Control"))
+	})
+
+	t.Run("Tags", func(t *testing.T) {
+		tags, err := c.GetTags(ctx, remotePath)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"env": "test"}, tags)
+
+		err = c.SetTags(ctx, remotePath, map[string]string{"env": "prod", "team": "storage"})
+		assert.NoError(t, err)
+
+		tags, err = c.GetTags(ctx, remotePath)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"env": "prod", "team": "storage"}, tags)
+
+		err = c.DeleteTags(ctx, remotePath)
+		assert.NoError(t, err)
+
+		tags, err = c.GetTags(ctx, remotePath)
+		assert.NoError(t, err)
+		assert.Empty(t, tags)
+	})
+
+	t.Run("UpdateMetadata", func(t *testing.T) {
+		err := c.UpdateMetadata(ctx, remotePath, &s3.PutOptions{
+			UserMetadata: map[string]string{"Owner": "updated"},
+		})
+		assert.NoError(t, err)
+
+		stat, err := c.Stat(ctx, remotePath)
+		assert.NoError(t, err)
+		assert.Equal(t, "updated", stat.UserMetadata["Owner"])
+		assert.Equal(t, "text/plain", stat.ContentType)
+		assert.Equal(t, "max-age=60", stat.Metadata.Get("Cache-Control"))
+		assert.Equal(t, int64(len(content)), stat.Size)
+	})
+}
+
+func TestClient_Versioning(t *testing.T) {
+	c, _, err := initE2EClient(t)
+	if err != nil {
+		t.Errorf("failed to init e2e client: %v", err)
+		return
+	}
+
+	ctx := context.Background()
+	prefix := "__e2e_test__/versioning/"
+	remotePath := prefix + "a.txt"
+
+	listVersions := func(t *testing.T) []minio.ObjectInfo {
+		var versions []minio.ObjectInfo
+		for obj, err := range c.ListVersions(ctx, prefix) {
+			assert.NoError(t, err)
+			versions = append(versions, obj)
+		}
+		return versions
+	}
+
+	for _, content := range []string{"v1", "v2"} {
+		err = c.Upload(ctx, remotePath, strings.NewReader(content), int64(len(content)), "text/plain")
+		assert.NoError(t, err)
+	}
+
+	t.Cleanup(func() {
+		// permanently delete every version and delete marker
+		for _, v := range listVersions(t) {
+			err := c.DeleteWithOptions(ctx, v.Key, &s3.DeleteOptions{VersionID: v.VersionID})
+			assert.NoError(t, err)
+		}
+	})
+
+	versions := listVersions(t)
+	if len(versions) < 2 {
+		t.Skip("bucket versioning is not enabled")
+	}
+
+	// newest first
+	latest, old := versions[0], versions[1]
+	assert.Equal(t, "a.txt", path.Base(latest.Key))
+	assert.True(t, latest.IsLatest)
+	assert.False(t, old.IsLatest)
+
+	t.Run("StatVersion", func(t *testing.T) {
+		info, err := c.StatWithOptions(ctx, remotePath, &s3.StatOptions{VersionID: old.VersionID})
+		assert.NoError(t, err)
+		assert.Equal(t, old.VersionID, info.VersionID)
+		assert.Equal(t, int64(2), info.Size)
+	})
+
+	t.Run("DownloadVersion", func(t *testing.T) {
+		ret, err := c.DownloadWithOptions(ctx, remotePath, &s3.DownloadOptions{VersionID: old.VersionID})
+		assert.NoError(t, err)
+		defer ret.Body.Close()
+
+		buf, err := io.ReadAll(ret.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, "v1", string(buf))
+	})
+
+	t.Run("DeleteMarker", func(t *testing.T) {
+		err := c.Delete(ctx, remotePath)
+		assert.NoError(t, err)
+
+		_, err = c.Stat(ctx, remotePath)
+		assert.ErrorIs(t, err, s3.ErrNotFound)
+
+		versions := listVersions(t)
+		if assert.Len(t, versions, 3) {
+			assert.True(t, versions[0].IsDeleteMarker)
+			assert.True(t, versions[0].IsLatest)
+			assert.Equal(t, latest.VersionID, versions[1].VersionID)
+		}
+	})
+
+	t.Run("Restore", func(t *testing.T) {
+		err := c.Restore(ctx, remotePath, old.VersionID)
+		assert.NoError(t, err)
+
+		r, err := c.Download(ctx, remotePath)
+		assert.NoError(t, err)
+		defer r.Close()
+
+		buf, err := io.ReadAll(r)
+		assert.NoError(t, err)
+		assert.Equal(t, "v1", string(buf))
+
+		// restored copy is a new version, the old one is kept
+		versions := listVersions(t)
+		if assert.Len(t, versions, 4) {
+			assert.False(t, versions[0].IsDeleteMarker)
+			assert.NotEqual(t, old.VersionID, versions[0].VersionID)
+			assert.Equal(t, old.VersionID, versions[3].VersionID)
+		}
+	})
+
+	t.Run("RestoreWithoutVersion", func(t *testing.T) {
+		err := c.Restore(ctx, remotePath, "")
+		assert.ErrorIs(t, err, s3.ErrInvalidArgument)
+	})
+}
+
+func TestClient_ObjectLock(t *testing.T) {
+	c, _, err := initE2EClient(t)
+	if err != nil {
+		t.Errorf("failed to init e2e client: %v", err)
+		return
+	}
+
+	ctx := context.Background()
+	remotePath := "__e2e_test__/object-lock/a.txt"
+
+	err = c.UploadWithOptions(ctx, remotePath, strings.NewReader("locked"), 6, &s3.PutOptions{
+		RetentionMode:   s3common.RetentionModeGovernance,
+		RetainUntilDate: time.Now().Add(time.Hour),
+		LegalHold:       true,
+	})
+	if errors.Is(err, s3.ErrNotFound) || errors.Is(err, s3.ErrNotSupported) || errors.Is(err, s3.ErrInvalidArgument) {
+		t.Skipf("bucket object lock is not enabled: %v", err)
+	}
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	info, err := c.Stat(ctx, remotePath)
+	assert.NoError(t, err)
+	versionID := info.VersionID
+
+	t.Cleanup(func() {
+		err := c.SetLegalHold(ctx, remotePath, versionID, false)
+		assert.NoError(t, err)
+
+		err = c.DeleteWithOptions(ctx, remotePath, &s3.DeleteOptions{VersionID: versionID, BypassGovernance: true})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Get", func(t *testing.T) {
+		retention, err := c.GetRetention(ctx, remotePath, versionID)
+		assert.NoError(t, err)
+		assert.Equal(t, s3common.RetentionModeGovernance, retention.Mode)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), retention.RetainUntilDate, time.Minute)
+
+		hold, err := c.GetLegalHold(ctx, remotePath, versionID)
+		assert.NoError(t, err)
+		assert.True(t, hold)
+	})
+
+	t.Run("DeleteLocked", func(t *testing.T) {
+		err := c.DeleteWithOptions(ctx, remotePath, &s3.DeleteOptions{VersionID: versionID})
+		assert.ErrorIs(t, err, s3.ErrObjectLocked)
+	})
+
+	t.Run("SetRetention", func(t *testing.T) {
+		until := time.Now().Add(2 * time.Hour)
+		err := c.SetRetention(ctx, remotePath, s3.Retention{Mode: s3common.RetentionModeGovernance, RetainUntilDate: until}, &s3.RetentionOptions{VersionID: versionID})
+		assert.NoError(t, err)
+
+		retention, err := c.GetRetention(ctx, remotePath, versionID)
+		assert.NoError(t, err)
+		assert.WithinDuration(t, until, retention.RetainUntilDate, time.Second)
+	})
+
+	t.Run("SetLegalHold", func(t *testing.T) {
+		err := c.SetLegalHold(ctx, remotePath, versionID, false)
+		assert.NoError(t, err)
+
+		hold, err := c.GetLegalHold(ctx, remotePath, versionID)
+		assert.NoError(t, err)
+		assert.False(t, hold)
+	})
+}
+
+func TestClient_CopyMultipart(t *testing.T) {
+	c, _, err := initE2EClient(t)
+	if err != nil {
+		t.Errorf("failed to init e2e client: %v", err)
+		return
+	}
+
+	ctx := context.Background()
+	srcPath := "__e2e_test__/copy-multipart/source.bin"
+	dstPath := "__e2e_test__/copy-multipart/copied.bin"
+	movedPath := "__e2e_test__/copy-multipart/moved.bin"
+
+	content := make([]byte, 12<<20)
+	_, _ = rand.Read(content)
+
+	err = c.UploadWithOptions(ctx, srcPath, bytes.NewReader(content), int64(len(content)), &s3.PutOptions{
+		ContentType:  "video/mp4",
+		UserMetadata: map[string]string{"Owner": "e2e"},
+		Tags:         map[string]string{"env": "test"},
+		CacheControl: "max-age=60",
+	})
+	assert.NoError(t, err)
+
+	t.Cleanup(func() {
+		_, err := c.DeleteMany(ctx, []string{srcPath, dstPath, movedPath})
+		assert.NoError(t, err)
+	})
+
+	// force multipart copy with small threshold
+	opts := &s3.CopyOptions{
+		MultipartThreshold: 5 << 20,
+		PartSize:           5 << 20,
+		Concurrency:        2,
+	}
+
+	t.Run("Copy", func(t *testing.T) {
+		err := c.CopyWithOptions(ctx, srcPath, dstPath, opts)
+		assert.NoError(t, err)
+
+		stat, err := c.Stat(ctx, dstPath)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(len(content)), stat.Size)
+		assert.Equal(t, "video/mp4", stat.ContentType)
+		assert.Equal(t, "e2e", stat.UserMetadata["Owner"])
+		assert.Equal(t, "max-age=60", stat.Metadata.Get("Cache-Control"))
+		assert.Contains(t, stat.ETag, "-3") // 3 parts
+
+		tags, err := c.GetTags(ctx, dstPath)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"env": "test"}, tags)
+
+		r, err := c.Download(ctx, dstPath)
+		assert.NoError(t, err)
+		defer r.Close()
+
+		buf, err := io.ReadAll(r)
+		assert.NoError(t, err)
+		assert.True(t, bytes.Equal(content, buf))
+	})
+
+	t.Run("Move", func(t *testing.T) {
+		err := c.Move(ctx, dstPath, movedPath)
+		assert.NoError(t, err)
+
+		_, err = c.Stat(ctx, dstPath)
+		assert.ErrorIs(t, err, s3.ErrNotFound)
+
+		stat, err := c.Stat(ctx, movedPath)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(len(content)), stat.Size)
+	})
+}
+
+func TestClient_CopyTo(t *testing.T) {
+	c, _, err := initE2EClient(t)
+	if err != nil {
+		t.Errorf("failed to init e2e client: %v", err)
+		return
+	}
+
+	// another client of the same bucket with different prefix
+	cfgContent, err := os.ReadFile(os.Getenv("E2E_S3_CONFIG"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dstCfg, err := s3.ParseConfig(cfgContent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dstCfg.Prefix = path.Join(dstCfg.Prefix, "__e2e_test__/copy-to-dst")
+	dst, err := s3.NewClient(dstCfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	srcPath := "__e2e_test__/copy-to/source.txt"
+	content := []byte("hello, copy to")
+
+	err = c.UploadWithOptions(ctx, srcPath, bytes.NewReader(content), int64(len(content)), &s3.PutOptions{
+		ContentType:  "text/plain",
+		UserMetadata: map[string]string{"Owner": "e2e"},
+	})
+	assert.NoError(t, err)
+
+	t.Cleanup(func() {
+		_, _ = c.DeleteMany(ctx, []string{srcPath})
+		_, _ = dst.DeleteMany(ctx, []string{"copied.txt", "moved.txt"})
+	})
+
+	t.Run("CopyTo", func(t *testing.T) {
+		var copied, total int64
+		err := c.CopyToWithOptions(ctx, srcPath, dst, "copied.txt", &s3.CopyOptions{
+			Progress: func(n, size int64) { copied, total = n, size },
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(len(content)), copied)
+		assert.Equal(t, int64(len(content)), total)
+
+		stat, err := dst.Stat(ctx, "copied.txt")
+		assert.NoError(t, err)
+		assert.Equal(t, "text/plain", stat.ContentType)
+		assert.Equal(t, "e2e", stat.UserMetadata["Owner"])
+	})
+
+	t.Run("CopyToStream", func(t *testing.T) {
+		// same service reached by another host name, forces download & upload
+		u, err := url.Parse(dstCfg.Endpoint)
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch u.Hostname() {
+		case "127.0.0.1":
+			u.Host = strings.Replace(u.Host, "127.0.0.1", "localhost", 1)
+		case "localhost":
+			u.Host = strings.Replace(u.Host, "localhost", "127.0.0.1", 1)
+		default:
+			t.Skipf("no alias for endpoint host %s", u.Hostname())
+		}
+
+		streamCfg := *dstCfg
+		streamCfg.Endpoint = u.String()
+		streamDst, err := s3.NewClient(&streamCfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		same, err := c.SameService(ctx, streamDst)
+		assert.NoError(t, err)
+		assert.False(t, same)
+
+		t.Cleanup(func() { _, _ = streamDst.DeleteMany(ctx, []string{"streamed.txt"}) })
+
+		var copied, total int64
+		err = c.CopyToWithOptions(ctx, srcPath, streamDst, "streamed.txt", &s3.CopyOptions{
+			Progress: func(n, size int64) { copied, total = n, size },
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(len(content)), copied)
+		assert.Equal(t, int64(len(content)), total)
+
+		stat, err := streamDst.Stat(ctx, "streamed.txt")
+		assert.NoError(t, err)
+		assert.Equal(t, "text/plain", stat.ContentType)
+		assert.Equal(t, "e2e", stat.UserMetadata["Owner"])
+
+		rc, err := streamDst.Download(ctx, "streamed.txt")
+		if assert.NoError(t, err) {
+			defer rc.Close()
+			got, err := io.ReadAll(rc)
+			assert.NoError(t, err)
+			assert.Equal(t, content, got)
+		}
+	})
+
+	t.Run("MoveTo", func(t *testing.T) {
+		err := c.MoveTo(ctx, srcPath, dst, "moved.txt")
+		assert.NoError(t, err)
+
+		_, err = c.Stat(ctx, srcPath)
+		assert.ErrorIs(t, err, s3.ErrNotFound)
+
+		stat, err := dst.Stat(ctx, "moved.txt")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(len(content)), stat.Size)
+	})
+}
+
+func TestClient_Lifecycle(t *testing.T) {
+	c, _, err := initE2EClient(t)
+	if err != nil {
+		t.Errorf("failed to init e2e client: %v", err)
+		return
+	}
+
+	ctx := context.Background()
+
+	t.Cleanup(func() {
+		err := c.PutLifecycleRules(ctx, nil)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Put", func(t *testing.T) {
+		err := c.PutLifecycleRules(ctx, []s3.LifecycleRule{
+			{ID: "e2e-expire-tmp", Prefix: "__e2e_test__/tmp/", ExpirationDays: 1},
+		})
+		assert.NoError(t, err)
+
+		rules, err := c.GetLifecycleRules(ctx)
+		assert.NoError(t, err)
+		if assert.Len(t, rules, 1) {
+			assert.Equal(t, "e2e-expire-tmp", rules[0].ID)
+			assert.Equal(t, "__e2e_test__/tmp/", rules[0].Prefix)
+			assert.Equal(t, 1, rules[0].ExpirationDays)
+		}
+	})
+
+	t.Run("Merge", func(t *testing.T) {
+		err := c.MergeLifecycleRules(ctx, []s3.LifecycleRule{
+			{ID: "e2e-expire-tmp", Prefix: "__e2e_test__/tmp/", ExpirationDays: 7},
+			{ID: "e2e-expire-cache", Prefix: "__e2e_test__/cache/", ExpirationDays: 3},
+		})
+		assert.NoError(t, err)
+
+		rules, err := c.GetLifecycleRules(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, rules, 2)
+		for _, r := range rules {
+			if r.ID == "e2e-expire-tmp" {
+				assert.Equal(t, 7, r.ExpirationDays)
+			}
+		}
+	})
+
+	t.Run("InvalidRule", func(t *testing.T) {
+		err := c.PutLifecycleRules(ctx, []s3.LifecycleRule{{ID: "no-action"}})
+		assert.ErrorIs(t, err, s3.ErrInvalidArgument)
+	})
+}
+
+func TestClient_UploadCORS(t *testing.T) {
+	c, _, err := initE2EClient(t)
+	if err != nil {
+		t.Errorf("failed to init e2e client: %v", err)
+		return
+	}
+
+	ctx := context.Background()
+	opts := &s3.UploadCORSOptions{
+		ID:             "e2e-upload",
+		AllowedOrigins: []string{"https://app.example.com"},
+	}
+
+	expect, err := c.UploadCORSRule(opts)
+	assert.NoError(t, err)
+	assert.Contains(t, expect.ExposeHeaders, "etag")
+
+	// ensure twice, the second call should be a no-op
+	for range 2 {
+		err = c.EnsureUploadCORS(ctx, opts)
+		if errors.Is(err, s3.ErrNotSupported) {
+			t.Skip("bucket cors is not supported")
+		}
+		assert.NoError(t, err)
+	}
+
+	rules, err := c.GetCORSRules(ctx)
+	assert.NoError(t, err)
+
+	var found bool
+	for _, r := range rules {
+		if r.ID == expect.ID {
+			found = true
+			assert.ElementsMatch(t, expect.AllowedMethods, r.AllowedMethods)
+			assert.Equal(t, expect.AllowedOrigins, r.AllowedOrigins)
+		}
+	}
+	assert.True(t, found)
+}
+
+func TestClient_ListWalk(t *testing.T) {
+	c, tempDir, err := initE2EClient(t)
+	if err != nil {
+		t.Errorf("failed to init e2e client: %v", err)
+		return
+	}
+
+	origPath := filepath.Join(tempDir, localPathRandom)
+	remoteDir := "__e2e_test__/list-walk/"
+	remotePaths := []string{
+		remoteDir + "a.bin",
+		remoteDir + "b.bin",
+		remoteDir + "sub/c.bin",
+	}
+
+	for _, p := range remotePaths {
+		err := c.UploadFile(context.Background(), p, origPath)
+		assert.NoError(t, err)
+	}
+
+	t.Cleanup(func() {
+		for _, p := range remotePaths {
+			err := c.Delete(context.Background(), p)
+			assert.NoError(t, err)
+		}
+	})
+
+	t.Run("List", func(t *testing.T) {
+		ret, err := c.List(context.Background(), remoteDir, nil)
+		assert.NoError(t, err)
+
+		keys := make([]string, 0, len(ret.Objects))
+		for _, obj := range ret.Objects {
+			keys = append(keys, obj.Key)
+		}
+		assert.Equal(t, remotePaths[:2], keys)
+		assert.Equal(t, []string{remoteDir + "sub/"}, ret.Prefixes)
+	})
+
+	t.Run("ListPagination", func(t *testing.T) {
+		opts := &s3.ListOptions{Recursive: true, MaxKeys: 2}
+
+		keys := make([]string, 0, len(remotePaths))
+		for {
+			ret, err := c.List(context.Background(), remoteDir, opts)
+			assert.NoError(t, err)
+			if err != nil {
+				return
+			}
+
+			for _, obj := range ret.Objects {
+				keys = append(keys, obj.Key)
+			}
+
+			if !ret.IsTruncated {
+				break
+			}
+			opts.ContinuationToken = ret.NextContinuationToken
+		}
+		assert.Equal(t, remotePaths, keys)
+	})
+
+	t.Run("Walk", func(t *testing.T) {
+		keys := make([]string, 0, len(remotePaths))
+		for obj, err := range c.Walk(context.Background(), remoteDir) {
+			assert.NoError(t, err)
+			keys = append(keys, obj.Key)
+		}
+		assert.Equal(t, remotePaths, keys)
+	})
+}
+
+func TestClient_DeleteManyPrefix(t *testing.T) {
+	c, tempDir, err := initE2EClient(t)
+	if err != nil {
+		t.Errorf("failed to init e2e client: %v", err)
+		return
+	}
+
+	origPath := filepath.Join(tempDir, localPathRandom)
+	remoteDir := "__e2e_test__/delete-many/"
+	remotePaths := []string{
+		remoteDir + "a.bin",
+		remoteDir + "b.bin",
+		remoteDir + "sub/c.bin",
+		remoteDir + "sub/d.bin",
+	}
+
+	for _, p := range remotePaths {
+		err := c.UploadFile(context.Background(), p, origPath)
+		assert.NoError(t, err)
+	}
+
+	t.Run("DeleteMany", func(t *testing.T) {
+		failed, err := c.DeleteMany(context.Background(), remotePaths[:2])
+		assert.NoError(t, err)
+		assert.Empty(t, failed)
+
+		ret, err := c.List(context.Background(), remoteDir, &s3.ListOptions{Recursive: true})
+		assert.NoError(t, err)
+		assert.Len(t, ret.Objects, 2)
+	})
+
+	t.Run("DeletePrefix", func(t *testing.T) {
+		failed, err := c.DeletePrefix(context.Background(), remoteDir)
+		assert.NoError(t, err)
+		assert.Empty(t, failed)
+
+		ret, err := c.List(context.Background(), remoteDir, &s3.ListOptions{Recursive: true})
+		assert.NoError(t, err)
+		assert.Empty(t, ret.Objects)
+	})
+}
+
+func TestClient_UploadFileMultipart(t *testing.T) {
+	c, tempDir, err := initE2EClient(t)
+	if err != nil {
+		t.Errorf("failed to init e2e client: %v", err)
+		return
+	}
+
+	origPath := filepath.Join(tempDir, "multipart.bin")
+	downloadPath := filepath.Join(tempDir, "multipart_download.bin")
+	remoteDir := "__e2e_test__/multipart/"
+	remotePath := remoteDir + "uploaded.bin"
+
+	{ // 12 MiB, 3 parts with 5 MiB part size
+		buf := make([]byte, 12<<20)
+		_, err := rand.Read(buf)
+		assert.NoError(t, err)
+		assert.NoError(t, os.WriteFile(origPath, buf, 0o644))
+	}
+
+	store, err := s3.NewMultipartFileStore(filepath.Join(tempDir, "checkpoint"))
+	assert.NoError(t, err)
+
+	t.Run("UploadFileMultipart", func(t *testing.T) {
+		err := c.UploadFileMultipart(context.Background(), remotePath, origPath, &s3.MultipartOptions{
+			PartSize:    5 << 20,
+			Concurrency: 2,
+			Store:       store,
+		})
+		assert.NoError(t, err)
+
+		t.Cleanup(func() {
+			err := c.Delete(context.Background(), remotePath)
+			assert.NoError(t, err)
+		})
+
+		err = c.DownloadFile(context.Background(), remotePath, downloadPath)
+		assert.NoError(t, err)
+	})
+
 	t.Run("FileContentSame", func(t *testing.T) {
 		assertFileContentSame(t, origPath, downloadPath)
 	})
 
+	// interrupted returns a store which cancels the upload once the first part is saved
+	interrupted := func(cancel context.CancelFunc) *recordStore {
+		return &recordStore{MultipartStore: store, afterSave: func(cp *s3.MultipartCheckpoint) {
+			if len(cp.Parts) == 1 {
+				cancel()
+			}
+		}}
+	}
+
+	resumePath := remoteDir + "resumed.bin"
+	t.Cleanup(func() { _ = c.Delete(context.Background(), resumePath) })
+
+	t.Run("Resume", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		first := interrupted(cancel)
+		err := c.UploadFileMultipart(ctx, resumePath, origPath, &s3.MultipartOptions{
+			PartSize:    5 << 20,
+			Concurrency: 1,
+			Store:       first,
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, []int{1}, first.partNumbers)
+
+		second := &recordStore{MultipartStore: store}
+		err = c.UploadFileMultipart(context.Background(), resumePath, origPath, &s3.MultipartOptions{
+			PartSize:    5 << 20,
+			Concurrency: 1,
+			Store:       second,
+		})
+		assert.NoError(t, err)
+
+		// only missing parts are uploaded to the same upload
+		assert.Equal(t, []int{2, 3}, second.partNumbers)
+		assert.Equal(t, first.uploadID, second.uploadID)
+
+		err = c.DownloadFile(context.Background(), resumePath, downloadPath)
+		assert.NoError(t, err)
+		assertFileContentSame(t, origPath, downloadPath)
+	})
+
+	t.Run("RestartOnFileChanged", func(t *testing.T) {
+		changedPath := filepath.Join(tempDir, "changed.bin")
+		buf := make([]byte, 12<<20)
+		_, _ = rand.Read(buf)
+		assert.NoError(t, os.WriteFile(changedPath, buf, 0o644))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		first := interrupted(cancel)
+		err := c.UploadFileMultipart(ctx, resumePath, changedPath, &s3.MultipartOptions{
+			PartSize:      5 << 20,
+			Concurrency:   1,
+			Store:         first,
+			CheckpointKey: "changed",
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+
+		// same size, different content and modification time
+		_, _ = rand.Read(buf)
+		assert.NoError(t, os.WriteFile(changedPath, buf, 0o644))
+		assert.NoError(t, os.Chtimes(changedPath, time.Now(), time.Now().Add(time.Hour)))
+
+		second := &recordStore{MultipartStore: store}
+		err = c.UploadFileMultipart(context.Background(), resumePath, changedPath, &s3.MultipartOptions{
+			PartSize:      5 << 20,
+			Concurrency:   1,
+			Store:         second,
+			CheckpointKey: "changed",
+		})
+		assert.NoError(t, err)
+
+		// previous upload is aborted, all parts are uploaded again
+		assert.Equal(t, []int{1, 2, 3}, second.partNumbers)
+		assert.NotEqual(t, first.uploadID, second.uploadID)
+		for u, err := range c.ListIncompleteUploads(context.Background(), remoteDir) {
+			assert.NoError(t, err)
+			assert.NotEqual(t, first.uploadID, u.UploadID)
+		}
+
+		err = c.DownloadFile(context.Background(), resumePath, downloadPath)
+		assert.NoError(t, err)
+		assertFileContentSame(t, changedPath, downloadPath)
+	})
+
+	t.Run("ResumeReaderChanged", func(t *testing.T) {
+		buf, err := os.ReadFile(origPath)
+		assert.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		first := interrupted(cancel)
+		err = c.UploadMultipart(ctx, resumePath, bytes.NewReader(buf), int64(len(buf)), &s3.MultipartOptions{
+			PartSize:    5 << 20,
+			Concurrency: 1,
+			Store:       first,
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, []int{1}, first.partNumbers)
+
+		// same size, content of the uploaded part changed
+		changed := bytes.Clone(buf)
+		_, _ = rand.Read(changed[:1<<20])
+
+		second := &recordStore{MultipartStore: store}
+		err = c.UploadMultipart(context.Background(), resumePath, bytes.NewReader(changed), int64(len(changed)), &s3.MultipartOptions{
+			PartSize:    5 << 20,
+			Concurrency: 1,
+			Store:       second,
+		})
+		assert.NoError(t, err)
+
+		// changed part is uploaded again to the same upload
+		assert.Equal(t, []int{1, 2, 3}, second.partNumbers)
+		assert.Equal(t, first.uploadID, second.uploadID)
+
+		got, err := c.Download(context.Background(), resumePath)
+		assert.NoError(t, err)
+		defer got.Close()
+		gotBuf, err := io.ReadAll(got)
+		assert.NoError(t, err)
+		assert.True(t, bytes.Equal(changed, gotBuf))
+	})
+
+	t.Run("AbortDeletesCheckpoint", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		first := interrupted(cancel)
+		err := c.UploadFileMultipart(ctx, resumePath, origPath, &s3.MultipartOptions{
+			PartSize:    5 << 20,
+			Concurrency: 1,
+			Store:       first,
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+
+		cp, err := store.Load(context.Background(), first.key)
+		assert.NoError(t, err)
+		assert.NotNil(t, cp)
+
+		n, err := c.CleanupIncompleteUploads(context.Background(), remoteDir, 0, store)
+		assert.NoError(t, err)
+		assert.Positive(t, n)
+
+		cp, err = store.Load(context.Background(), first.key)
+		assert.NoError(t, err)
+		assert.Nil(t, cp)
+	})
+
+	t.Run("CleanupIncompleteUploads", func(t *testing.T) {
+		_, err := c.CleanupIncompleteUploads(context.Background(), remoteDir, 0, nil)
+		assert.NoError(t, err)
+
+		for u, err := range c.ListIncompleteUploads(context.Background(), remoteDir) {
+			assert.NoError(t, err)
+			t.Errorf("unexpected incomplete upload: %s %s", u.Path, u.UploadID)
+		}
+	})
+}
+
+func TestClient_DownloadWithOptions(t *testing.T) {
+	c, tempDir, err := initE2EClient(t)
+	if err != nil {
+		t.Errorf("failed to init e2e client: %v", err)
+		return
+	}
+
+	origPath := filepath.Join(tempDir, localPathRandom)
+	origBuf, err := os.ReadFile(origPath)
+	assert.NoError(t, err)
+
+	remotePath := "__e2e_test__/download-options/uploaded.bin"
+
+	err = c.UploadFile(context.Background(), remotePath, origPath)
+	assert.NoError(t, err)
+
+	t.Cleanup(func() {
+		err := c.Delete(context.Background(), remotePath)
+		assert.NoError(t, err)
+	})
+
+	stat, err := c.Stat(context.Background(), remotePath)
+	assert.NoError(t, err)
+
+	readAll := func(t *testing.T, opts *s3.DownloadOptions) (*s3.DownloadResult, []byte) {
+		ret, err := c.DownloadWithOptions(context.Background(), remotePath, opts)
+		assert.NoError(t, err)
+		if err != nil {
+			return nil, nil
+		}
+		defer ret.Body.Close()
+
+		buf, err := io.ReadAll(ret.Body)
+		assert.NoError(t, err)
+		return ret, buf
+	}
+
+	t.Run("Range", func(t *testing.T) {
+		ret, buf := readAll(t, &s3.DownloadOptions{Offset: 100, Length: 200})
+		assert.Equal(t, origBuf[100:300], buf)
+		assert.Equal(t, int64(len(origBuf)), ret.TotalSize)
+		assert.Equal(t, fmt.Sprintf("bytes 100-299/%d", len(origBuf)), ret.ContentRange)
+	})
+
+	t.Run("Offset", func(t *testing.T) {
+		_, buf := readAll(t, &s3.DownloadOptions{Offset: 1000})
+		assert.Equal(t, origBuf[1000:], buf)
+	})
+
+	t.Run("Suffix", func(t *testing.T) {
+		_, buf := readAll(t, &s3.DownloadOptions{SuffixLength: 24})
+		assert.Equal(t, origBuf[len(origBuf)-24:], buf)
+	})
+
+	t.Run("IfMatch", func(t *testing.T) {
+		_, buf := readAll(t, &s3.DownloadOptions{IfMatch: stat.ETag})
+		assert.Equal(t, origBuf, buf)
+
+		_, err := c.DownloadWithOptions(context.Background(), remotePath, &s3.DownloadOptions{IfMatch: "mismatch"})
+		assert.ErrorIs(t, err, s3.ErrPreconditionFailed)
+	})
+
+	t.Run("IfNoneMatch", func(t *testing.T) {
+		_, err := c.DownloadWithOptions(context.Background(), remotePath, &s3.DownloadOptions{IfNoneMatch: stat.ETag})
+		assert.ErrorIs(t, err, s3.ErrNotModified)
+	})
+
+	t.Run("IfModifiedSince", func(t *testing.T) {
+		_, err := c.DownloadWithOptions(context.Background(), remotePath, &s3.DownloadOptions{
+			IfModifiedSince: stat.LastModified.Add(time.Hour),
+		})
+		assert.ErrorIs(t, err, s3.ErrNotModified)
+	})
+}
+
+func TestClient_DownloadFileWithOptions(t *testing.T) {
+	c, tempDir, err := initE2EClient(t)
+	if err != nil {
+		t.Errorf("failed to init e2e client: %v", err)
+		return
+	}
+
+	origPath := filepath.Join(tempDir, localPathRandom)
+	downloadPath := filepath.Join(tempDir, "download-parallel/download.bin")
+	remotePath := "__e2e_test__/download-parallel/uploaded.bin"
+
+	err = c.UploadFile(context.Background(), remotePath, origPath)
+	assert.NoError(t, err)
+
+	t.Cleanup(func() {
+		err := c.Delete(context.Background(), remotePath)
+		assert.NoError(t, err)
+	})
+
+	t.Run("DownloadFileWithOptions", func(t *testing.T) {
+		// 1KB file is downloaded in 10 ranges
+		err := c.DownloadFileWithOptions(context.Background(), remotePath, downloadPath, &s3.DownloadFileOptions{
+			PartSize:    100,
+			Concurrency: 3,
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("FileContentSame", func(t *testing.T) {
+		assertFileContentSame(t, origPath, downloadPath)
+	})
 }
 
 func TestClient_GenerateDownload(t *testing.T) {
@@ -229,6 +1190,112 @@ func TestClient_GenerateUpload(t *testing.T) {
 	})
 }
 
+func TestClient_GenerateMultipartUpload(t *testing.T) {
+	c, tempDir, err := initE2EClient(t)
+	if err != nil {
+		t.Errorf("failed to init e2e client: %v", err)
+		return
+	}
+
+	const partSize = 5 << 20
+
+	origPath := filepath.Join(tempDir, "generate-multipart.bin")
+	origBuf := make([]byte, 12<<20) // 3 parts
+	_, err = rand.Read(origBuf)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(origPath, origBuf, 0o644))
+
+	var partSha256 [][]byte
+	for offset := 0; offset < len(origBuf); offset += partSize {
+		sum := sha256.Sum256(origBuf[offset:min(offset+partSize, len(origBuf))])
+		partSha256 = append(partSha256, sum[:])
+	}
+
+	downloadPath := filepath.Join(tempDir, "generate-multipart_download.bin")
+	remotePath := "/__unit_test/generate-multipart/random.bin"
+
+	t.Run("GenerateMultipartUpload", func(t *testing.T) {
+		info, err := c.GenerateMultipartUpload(t.Context(), &s3up.MultipartGenerateParams{
+			RemotePath:  remotePath,
+			ExpireIn:    time.Minute,
+			Size:        int64(len(origBuf)),
+			PartSize:    partSize,
+			ContentType: "application/octet-stream",
+			PartSha256:  partSha256,
+		})
+		assert.NoError(t, err)
+		if err != nil {
+			return
+		}
+
+		completeParts := make([]s3up.MultipartCompletePart, 0, len(info.Parts))
+		for i, part := range info.Parts {
+			offset := int64(i) * info.PartSize
+
+			req, err := http.NewRequest(part.Method, part.URL.String(), bytes.NewReader(origBuf[offset:offset+part.Size]))
+			assert.NoError(t, err)
+			req.Header = part.Header
+
+			resp, err := http.DefaultClient.Do(req)
+			assert.NoError(t, err)
+			_ = resp.Body.Close()
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+			completeParts = append(completeParts, s3up.MultipartCompletePart{
+				PartNumber: part.PartNumber,
+				ETag:       resp.Header.Get("ETag"),
+				Sha256:     partSha256[i],
+			})
+		}
+
+		err = c.CompleteMultipartUpload(t.Context(), &s3up.MultipartCompleteParams{
+			RemotePath: remotePath,
+			UploadID:   info.UploadID,
+			Size:       int64(len(origBuf)),
+			Parts:      completeParts,
+		})
+		assert.NoError(t, err)
+
+		t.Cleanup(func() {
+			err := c.Delete(context.Background(), remotePath)
+			assert.NoError(t, err)
+		})
+
+		err = c.DownloadFile(context.Background(), remotePath, downloadPath)
+		assert.NoError(t, err)
+	})
+
+	t.Run("FileContentSame", func(t *testing.T) {
+		assertFileContentSame(t, origPath, downloadPath)
+	})
+}
+
+// recordStore 记录每次保存时新上传的分片
+type recordStore struct {
+	s3.MultipartStore
+	afterSave func(cp *s3.MultipartCheckpoint)
+
+	key         string
+	uploadID    string
+	partNumbers []int
+}
+
+func (s *recordStore) Save(ctx context.Context, key string, cp *s3.MultipartCheckpoint) error {
+	if err := s.MultipartStore.Save(ctx, key, cp); err != nil {
+		return err
+	}
+
+	s.key = key
+	s.uploadID = cp.UploadID
+	if len(cp.Parts) > 0 {
+		s.partNumbers = append(s.partNumbers, cp.Parts[len(cp.Parts)-1].PartNumber)
+	}
+	if s.afterSave != nil {
+		s.afterSave(cp)
+	}
+	return nil
+}
+
 func assertFileContentSame(t *testing.T, path1, path2 string) {
 	f1, err := os.ReadFile(path1)
 	assert.NoError(t, err)
@@ -18,6 +18,12 @@ type Config struct {
 	AccessKey string `json:"access_key"`
 	SecretKey string `json:"secret_key"`
 
+	// Credentials is optional, take precedence over AccessKey and SecretKey
+	Credentials *s3common.CredentialsConfig `json:"credentials"`
+
+	// Encryption is optional, default server-side encryption of uploaded and copied objects
+	Encryption *s3common.EncryptionConfig `json:"encryption"`
+
 	// UploadGenerator is optional, default to s3
 	UploadGeneratorType UploadGeneratorType `json:"upload_generator_type"`
 
@@ -33,10 +39,10 @@ type Config struct {
 
 func (c *Config) Validate() error {
 	if c.Endpoint == "" {
-		return fmt.Errorf("endpoint is required")
+		return &s3common.ConfigError{Field: "endpoint", Reason: "is required"}
 	}
 	if c.Bucket == "" {
-		return fmt.Errorf("bucket is required")
+		return &s3common.ConfigError{Field: "bucket", Reason: "is required"}
 	}
 
 	switch c.BucketLookup {
@@ -44,16 +50,28 @@ func (c *Config) Validate() error {
 		s3common.BucketLookupPath,
 		s3common.BucketLookupCNAME:
 	case "":
-		return fmt.Errorf("bucket_lookup is required")
+		return &s3common.ConfigError{Field: "bucket_lookup", Reason: "is required"}
 	default:
-		return fmt.Errorf("unknown bucket lookup type: %s", c.BucketLookup)
+		return &s3common.ConfigError{Field: "bucket_lookup", Reason: "is unknown: " + string(c.BucketLookup)}
 	}
 
-	if c.AccessKey == "" {
-		return fmt.Errorf("access_key is required")
+	if c.Credentials != nil {
+		if err := c.Credentials.Validate(); err != nil {
+			return err
+		}
+	} else {
+		if c.AccessKey == "" {
+			return &s3common.ConfigError{Field: "access_key", Reason: "is required"}
+		}
+		if c.SecretKey == "" {
+			return &s3common.ConfigError{Field: "secret_key", Reason: "is required"}
+		}
 	}
-	if c.SecretKey == "" {
-		return fmt.Errorf("secret_key is required")
+
+	if c.Encryption != nil {
+		if err := c.Encryption.Validate(); err != nil {
+			return err
+		}
 	}
 
 	if c.UploadGeneratorType == "" {
@@ -61,7 +79,7 @@ func (c *Config) Validate() error {
 	}
 	if c.UploadGeneratorType != UploadGeneratorTypeS3 {
 		if c.UploadGeneratorConfig == nil {
-			return fmt.Errorf("upload_generator_config is required")
+			return &s3common.ConfigError{Field: "upload_generator_config", Reason: "is required"}
 		}
 	}
 
@@ -70,7 +88,7 @@ func (c *Config) Validate() error {
 	}
 	if c.DownloadGeneratorType != DownloadGeneratorTypeS3 {
 		if c.DownloadGeneratorConfig == nil {
-			return fmt.Errorf("download_generator_config is required")
+			return &s3common.ConfigError{Field: "download_generator_config", Reason: "is required"}
 		}
 	}
 
@@ -0,0 +1,55 @@
+package s3
+
+import (
+	"fmt"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
+
+	"github.com/ix64/s3-go/s3common"
+)
+
+// writeSSE 返回写入对象时使用的加密方式，未指定时使用 Config.Encryption
+func (c *Client) writeSSE(sse encrypt.ServerSide) encrypt.ServerSide {
+	if sse == nil {
+		return c.sse
+	}
+	return sse
+}
+
+// readSSE 返回读取对象时需要提供的加密参数，仅 SSE-C 需要在读取时提供密钥
+func (c *Client) readSSE(sse encrypt.ServerSide) encrypt.ServerSide {
+	return onlySSEC(c.writeSSE(sse))
+}
+
+// onlySSEC SSE-S3 及 SSE-KMS 只能在创建对象时指定，其余请求携带加密头会被拒绝
+func onlySSEC(sse encrypt.ServerSide) encrypt.ServerSide {
+	if sse == nil || sse.Type() != encrypt.SSEC {
+		return nil
+	}
+	return sse
+}
+
+// objectSSE 根据对象的加密响应头还原其加密方式，用于原地复制时保持加密不变
+//
+// SSE-C 对象使用读取时提供的密钥 ssec；SSE-KMS 的加密上下文无法从响应头还原
+func objectSSE(info minio.ObjectInfo, ssec encrypt.ServerSide) (encrypt.ServerSide, error) {
+	if info.Metadata.Get("X-Amz-Server-Side-Encryption-Customer-Algorithm") != "" {
+		return ssec, nil
+	}
+
+	switch algo := info.Metadata.Get("X-Amz-Server-Side-Encryption"); algo {
+	case "":
+		return nil, nil
+	case "AES256":
+		return encrypt.NewSSE(), nil
+	case "aws:kms":
+		sse, err := encrypt.NewSSEKMS(info.Metadata.Get("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id"), nil)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid kms key id of object: %w", s3common.ErrInvalidArgument, err)
+		}
+		return sse, nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported server-side encryption of object: %s, set PutOptions.Encryption explicitly", s3common.ErrInvalidArgument, algo)
+	}
+}
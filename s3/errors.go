@@ -0,0 +1,26 @@
+package s3
+
+import (
+	"github.com/ix64/s3-go/s3common"
+)
+
+// 错误分类，定义于 s3common 以便 s3up 与 s3down 共用，参见 s3common.ConvertError
+var (
+	ErrNotFound           = s3common.ErrNotFound
+	ErrAccessDenied       = s3common.ErrAccessDenied
+	ErrInvalidCredentials = s3common.ErrInvalidCredentials
+	ErrPreconditionFailed = s3common.ErrPreconditionFailed
+	ErrNotModified        = s3common.ErrNotModified
+	ErrInvalidRange       = s3common.ErrInvalidRange
+	ErrConflict           = s3common.ErrConflict
+	ErrInvalidArgument    = s3common.ErrInvalidArgument
+	ErrChecksumMismatch   = s3common.ErrChecksumMismatch
+	ErrEntityTooLarge     = s3common.ErrEntityTooLarge
+	ErrEntityTooSmall     = s3common.ErrEntityTooSmall
+	ErrObjectLocked       = s3common.ErrObjectLocked
+	ErrNotSupported       = s3common.ErrNotSupported
+	ErrInvalidConfig      = s3common.ErrInvalidConfig
+)
+
+// ConfigError 配置校验错误，errors.Is(err, ErrInvalidConfig) 为 true
+type ConfigError = s3common.ConfigError
@@ -0,0 +1,11 @@
+package s3
+
+import "context"
+
+// SameService 供 s3_test 包判断 CopyTo 使用服务端复制还是流式复制
+func (c *Client) SameService(ctx context.Context, dst *Client) (bool, error) {
+	return c.sameService(ctx, dst)
+}
+
+// ObjectSSE 供 s3_test 包校验原地复制时还原的加密方式
+var ObjectSSE = objectSSE
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/ix64/s3-go/s3common"
 	s3down2 "github.com/ix64/s3-go/s3down"
 )
 
@@ -13,6 +14,13 @@ const (
 	DownloadGeneratorTypeS3              DownloadGeneratorType = "s3"
 	DownloadGeneratorTypeAliyunCDN       DownloadGeneratorType = "aliyun_cdn"
 	DownloadGeneratorTypeTencentCloudCDN DownloadGeneratorType = "tencent_cloud_cdn"
+	DownloadGeneratorTypeHuaweiCloudCDN  DownloadGeneratorType = "huawei_cloud_cdn"
+	DownloadGeneratorTypeHuaweiCloudOBS  DownloadGeneratorType = "huawei_cloud_obs"
+	DownloadGeneratorTypeCloudFront      DownloadGeneratorType = "cloudfront"
+	DownloadGeneratorTypeCloudflareCDN   DownloadGeneratorType = "cloudflare_cdn"
+	DownloadGeneratorTypeQiniuCDN        DownloadGeneratorType = "qiniu_cdn"
+	DownloadGeneratorTypeQiniuKodo       DownloadGeneratorType = "qiniu_kodo"
+	DownloadGeneratorTypeVolcengineCDN   DownloadGeneratorType = "volcengine_cdn"
 )
 
 func newDownloadGenerator(c *Client, t DownloadGeneratorType, raw json.RawMessage) (s3down2.Generator, error) {
@@ -41,8 +49,71 @@ func newDownloadGenerator(c *Client, t DownloadGeneratorType, raw json.RawMessag
 		}
 		return s3down2.NewGeneratorTencentCloudCDN(cfg)
 
+	case DownloadGeneratorTypeHuaweiCloudCDN:
+		cfg := &s3down2.GeneratorHuaweiCloudCDNConfig{}
+		if err := json.Unmarshal(raw, cfg); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+		}
+		return s3down2.NewGeneratorHuaweiCloudCDN(cfg)
+
+	case DownloadGeneratorTypeHuaweiCloudOBS:
+		cfg := &s3down2.GeneratorHuaweiCloudOBSConfig{}
+		if err := json.Unmarshal(raw, cfg); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+		}
+		if cfg.Bucket == "" {
+			cfg.Bucket = c.cfg.Bucket
+		}
+		if cfg.Prefix == "" {
+			cfg.Prefix = c.prefix
+		}
+		// same keys as S3 compatible api, session token is rejected on generating
+		if cfg.AccessKey == "" && cfg.SecretKey == "" && cfg.Creds == nil {
+			cfg.Creds = c.creds
+		}
+		return s3down2.NewGeneratorHuaweiCloudOBS(cfg)
+
+	case DownloadGeneratorTypeCloudFront:
+		cfg := &s3down2.GeneratorCloudFrontConfig{}
+		if err := json.Unmarshal(raw, cfg); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+		}
+		return s3down2.NewGeneratorCloudFront(cfg)
+
+	case DownloadGeneratorTypeCloudflareCDN:
+		cfg := &s3down2.GeneratorCloudflareCDNConfig{}
+		if err := json.Unmarshal(raw, cfg); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+		}
+		return s3down2.NewGeneratorCloudflareCDN(cfg)
+
+	case DownloadGeneratorTypeQiniuCDN:
+		cfg := &s3down2.GeneratorQiniuCDNConfig{}
+		if err := json.Unmarshal(raw, cfg); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+		}
+		return s3down2.NewGeneratorQiniuCDN(cfg)
+
+	case DownloadGeneratorTypeQiniuKodo:
+		cfg := &s3down2.GeneratorQiniuKodoConfig{}
+		if err := json.Unmarshal(raw, cfg); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+		}
+		// same keys as S3 compatible api, session token is rejected on generating
+		if cfg.AccessKey == "" && cfg.SecretKey == "" && cfg.Creds == nil {
+			cfg.Creds = c.creds
+		}
+		return s3down2.NewGeneratorQiniuKodo(cfg)
+
+	case DownloadGeneratorTypeVolcengineCDN:
+		cfg := &s3down2.GeneratorVolcengineCDNConfig{}
+		if err := json.Unmarshal(raw, cfg); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+		}
+		return s3down2.NewGeneratorVolcengineCDN(cfg)
+
 	default:
-		return nil, fmt.Errorf("unknown s3down Generator type: %s", t)
+		return nil, &s3common.ConfigError{Field: "download_generator_type", Reason: "is unknown: " + string(t)}
 	}
 }
 
@@ -65,11 +136,8 @@ func fillDownloadGeneratorS3Defaults(cfg *s3down2.GeneratorS3Config, c *Client)
 		cfg.Prefix = c.prefix
 	}
 
-	if cfg.AccessKey == "" {
-		cfg.AccessKey = c.cfg.AccessKey
-	}
-
-	if cfg.SecretKey == "" {
-		cfg.SecretKey = c.cfg.SecretKey
+	// share credentials with client unless generator has its own
+	if cfg.AccessKey == "" && cfg.SecretKey == "" && cfg.Credentials == nil && cfg.Creds == nil {
+		cfg.Creds = c.creds
 	}
 }
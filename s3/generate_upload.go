@@ -4,13 +4,15 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/ix64/s3-go/s3common"
 	"github.com/ix64/s3-go/s3up"
 )
 
 type UploadGeneratorType string
 
 const (
-	UploadGeneratorTypeS3 UploadGeneratorType = "s3"
+	UploadGeneratorTypeS3    UploadGeneratorType = "s3"
+	UploadGeneratorTypeQiniu UploadGeneratorType = "qiniu"
 )
 
 func newUploadGenerator(c *Client, t UploadGeneratorType, raw json.RawMessage) (s3up.Generator, error) {
@@ -22,14 +24,25 @@ func newUploadGenerator(c *Client, t UploadGeneratorType, raw json.RawMessage) (
 				return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 			}
 		}
-		fillUploadGeneratorS3Defaults(cfg, c)
+		if err := fillUploadGeneratorS3Defaults(cfg, c); err != nil {
+			return nil, err
+		}
 		return s3up.NewGeneratorS3(cfg)
+
+	case UploadGeneratorTypeQiniu:
+		cfg := &s3up.GeneratorQiniuConfig{}
+		if err := json.Unmarshal(raw, cfg); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+		}
+		fillUploadGeneratorQiniuDefaults(cfg, c)
+		return s3up.NewGeneratorQiniu(cfg)
+
 	default:
-		return nil, fmt.Errorf("unknown Upload Generator type: %s", t)
+		return nil, &s3common.ConfigError{Field: "upload_generator_type", Reason: "is unknown: " + string(t)}
 	}
 }
 
-func fillUploadGeneratorS3Defaults(cfg *s3up.GeneratorS3Config, c *Client) {
+func fillUploadGeneratorS3Defaults(cfg *s3up.GeneratorS3Config, c *Client) error {
 	if cfg.Region == "" {
 		cfg.Region = c.region
 	}
@@ -48,11 +61,35 @@ func fillUploadGeneratorS3Defaults(cfg *s3up.GeneratorS3Config, c *Client) {
 		cfg.Prefix = c.prefix
 	}
 
-	if cfg.AccessKey == "" {
-		cfg.AccessKey = c.cfg.AccessKey
+	// SSE-C key must not be exposed to presigned upload,
+	// refuse to silently issue unencrypted uploads which client can not read with SSE-C
+	if cfg.Encryption == nil && c.cfg.Encryption != nil {
+		if c.cfg.Encryption.Type == s3common.EncryptionTypeC {
+			return &s3common.ConfigError{Field: "upload_generator_config.encryption", Reason: "is required when encryption.type is sse-c"}
+		}
+		cfg.Encryption = c.cfg.Encryption
+	}
+
+	// share credentials with client unless generator has its own
+	if cfg.AccessKey == "" && cfg.SecretKey == "" && cfg.Credentials == nil && cfg.Creds == nil {
+		cfg.Creds = c.creds
+	}
+
+	return nil
+}
+
+// fillUploadGeneratorQiniuDefaults 七牛 S3 兼容接口与上传凭证使用相同的 AccessKey 及 SecretKey，
+// 未单独配置时共享客户端凭证（包括 Config.Credentials），生成时获取到临时凭证会返回 ErrInvalidCredentials
+func fillUploadGeneratorQiniuDefaults(cfg *s3up.GeneratorQiniuConfig, c *Client) {
+	if cfg.Bucket == "" {
+		cfg.Bucket = c.cfg.Bucket
+	}
+
+	if cfg.Prefix == "" {
+		cfg.Prefix = c.prefix
 	}
 
-	if cfg.SecretKey == "" {
-		cfg.SecretKey = c.cfg.SecretKey
+	if cfg.AccessKey == "" && cfg.SecretKey == "" && cfg.Creds == nil {
+		cfg.Creds = c.creds
 	}
 }
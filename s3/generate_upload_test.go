@@ -0,0 +1,45 @@
+package s3_test
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ix64/s3-go/s3"
+	"github.com/ix64/s3-go/s3common"
+)
+
+func TestNewClient_UploadGeneratorSSEC(t *testing.T) {
+	newConfig := func(generatorConfig string) *s3.Config {
+		return &s3.Config{
+			Endpoint:     "http://127.0.0.1:1",
+			Bucket:       "my-bucket",
+			BucketLookup: s3common.BucketLookupPath,
+			Region:       "us-east-1",
+			AccessKey:    "ak",
+			SecretKey:    "sk",
+			Encryption: &s3common.EncryptionConfig{
+				Type:        s3common.EncryptionTypeC,
+				CustomerKey: base64.StdEncoding.EncodeToString(make([]byte, 32)),
+			},
+			UploadGeneratorConfig: []byte(generatorConfig),
+		}
+	}
+
+	t.Run("Implicit", func(t *testing.T) {
+		_, err := s3.NewClient(newConfig(`{}`))
+		assert.ErrorIs(t, err, s3.ErrInvalidConfig)
+
+		var cfgErr *s3.ConfigError
+		if assert.True(t, errors.As(err, &cfgErr)) {
+			assert.Equal(t, "upload_generator_config.encryption", cfgErr.Field)
+		}
+	})
+
+	t.Run("Explicit", func(t *testing.T) {
+		_, err := s3.NewClient(newConfig(`{"encryption":{"type":"sse-s3"}}`))
+		assert.NoError(t, err)
+	})
+}
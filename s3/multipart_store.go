@@ -0,0 +1,164 @@
+package s3
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// MultipartCheckpoint 分片上传的断点信息，用于进程重启后继续上传
+type MultipartCheckpoint struct {
+	UploadID   string `json:"upload_id"`
+	ObjectName string `json:"object_name"`
+
+	// Size 和 ModTime 用于判断本地文件是否发生变化
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+
+	PartSize int64                     `json:"part_size"`
+	Parts    []MultipartCheckpointPart `json:"parts"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type MultipartCheckpointPart struct {
+	PartNumber int    `json:"part_number"`
+	Size       int64  `json:"size"`
+	ETag       string `json:"etag"`
+
+	// Sha256 分片内容的 sha256 (hex)，上传时作为 x-amz-content-sha256 由服务端校验
+	Sha256 string `json:"sha256"`
+}
+
+// MultipartStore 持久化 MultipartCheckpoint，可自行实现以保存到数据库等位置
+type MultipartStore interface {
+	// Load 读取断点信息，不存在时返回 nil, nil
+	Load(ctx context.Context, key string) (*MultipartCheckpoint, error)
+
+	Save(ctx context.Context, key string, cp *MultipartCheckpoint) error
+
+	// Delete 删除断点信息，不存在时不返回错误
+	Delete(ctx context.Context, key string) error
+}
+
+// MultipartFileStore 将断点信息以 JSON 文件的形式保存在本地目录中
+type MultipartFileStore struct {
+	dir string
+}
+
+func NewMultipartFileStore(dir string) (*MultipartFileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create checkpoint dir: %w", err)
+	}
+	return &MultipartFileStore{dir: dir}, nil
+}
+
+func (s *MultipartFileStore) filename(key string) string {
+	// hash key to avoid path escape and invalid characters
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (s *MultipartFileStore) Load(_ context.Context, key string) (*MultipartCheckpoint, error) {
+	buf, err := os.ReadFile(s.filename(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	cp := &MultipartCheckpoint{}
+	if err := json.Unmarshal(buf, cp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal checkpoint: %w", err)
+	}
+	return cp, nil
+}
+
+func (s *MultipartFileStore) Save(_ context.Context, key string, cp *MultipartCheckpoint) error {
+	buf, err := json.Marshal(cp)
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint: %w", err)
+	}
+
+	// write to temp file then rename, to avoid corrupted checkpoint on crash
+	name := s.filename(key)
+	f, err := os.CreateTemp(s.dir, filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) // no-op after rename
+
+	if _, err := f.Write(buf); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), name)
+}
+
+func (s *MultipartFileStore) Delete(_ context.Context, key string) error {
+	err := os.Remove(s.filename(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// MultipartMemoryStore 将断点信息保存在内存中，仅能在同一进程内恢复上传
+type MultipartMemoryStore struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+func NewMultipartMemoryStore() *MultipartMemoryStore {
+	return &MultipartMemoryStore{data: make(map[string][]byte)}
+}
+
+func (s *MultipartMemoryStore) Load(_ context.Context, key string) (*MultipartCheckpoint, error) {
+	s.mu.Lock()
+	buf, ok := s.data[key]
+	s.mu.Unlock()
+
+	if !ok {
+		return nil, nil
+	}
+
+	// store serialized copy, caller can not modify stored checkpoint
+	cp := &MultipartCheckpoint{}
+	if err := json.Unmarshal(buf, cp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal checkpoint: %w", err)
+	}
+	return cp, nil
+}
+
+func (s *MultipartMemoryStore) Save(_ context.Context, key string, cp *MultipartCheckpoint) error {
+	buf, err := json.Marshal(cp)
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint: %w", err)
+	}
+
+	s.mu.Lock()
+	s.data[key] = buf
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *MultipartMemoryStore) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	delete(s.data, key)
+	s.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,57 @@
+package s3_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ix64/s3-go/s3"
+)
+
+func TestMultipartStore(t *testing.T) {
+	fileStore, err := s3.NewMultipartFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stores := map[string]s3.MultipartStore{
+		"File":   fileStore,
+		"Memory": s3.NewMultipartMemoryStore(),
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			key := "bucket/__unit_test__/multipart.bin"
+
+			cp, err := store.Load(ctx, key)
+			assert.NoError(t, err)
+			assert.Nil(t, cp)
+
+			saved := &s3.MultipartCheckpoint{
+				UploadID:   "upload-id",
+				ObjectName: "__unit_test__/multipart.bin",
+				Size:       12 << 20,
+				ModTime:    time.Unix(1700000000, 0).UTC(),
+				PartSize:   5 << 20,
+				Parts: []s3.MultipartCheckpointPart{
+					{PartNumber: 1, Size: 5 << 20, ETag: "etag-1", Sha256: "sha256-1"},
+				},
+			}
+			assert.NoError(t, store.Save(ctx, key, saved))
+
+			cp, err = store.Load(ctx, key)
+			assert.NoError(t, err)
+			assert.Equal(t, saved, cp)
+
+			assert.NoError(t, store.Delete(ctx, key))
+			assert.NoError(t, store.Delete(ctx, key))
+
+			cp, err = store.Load(ctx, key)
+			assert.NoError(t, err)
+			assert.Nil(t, cp)
+		})
+	}
+}
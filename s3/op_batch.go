@@ -0,0 +1,110 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"iter"
+
+	"github.com/minio/minio-go/v7"
+
+	"github.com/ix64/s3-go/s3common"
+)
+
+var errDeleteWholeBucket = fmt.Errorf("%w: prefix is empty, refuse to delete the whole bucket", s3common.ErrInvalidArgument)
+
+// DeleteError 批量删除时单个文件的删除错误
+type DeleteError struct {
+	Path string
+	Err  error
+}
+
+func (e *DeleteError) Error() string {
+	return fmt.Sprintf("failed to delete %s: %v", e.Path, e.Err)
+}
+
+func (e *DeleteError) Unwrap() error {
+	return e.Err
+}
+
+// DeleteMany 批量删除文件，返回删除失败的文件列表
+//
+// 使用 S3 Multi-Object Delete API，每个请求最多包含 1000 个文件
+func (c *Client) DeleteMany(ctx context.Context, paths []string) ([]*DeleteError, error) {
+	objects := func(yield func(minio.ObjectInfo) bool) {
+		for _, p := range paths {
+			if !yield(minio.ObjectInfo{Key: c.composeObjectName(p)}) {
+				return
+			}
+		}
+	}
+
+	return c.removeObjects(ctx, objects)
+}
+
+// DeletePrefix 删除指定前缀下的所有文件，返回删除失败的文件列表
+//
+// prefix 与 List 相同按字符串前缀匹配，"user/12" 会同时删除 "user/12.txt" 及 "user/123/" 下的文件，
+// 只删除目录 "user/12" 下的文件时需传入 "user/12/"
+//
+// prefix 与 Config.Prefix 均为空时会删除整个 bucket，prefix 跳出 Config.Prefix 时会删除其他服务的文件，
+// 均返回 ErrInvalidArgument
+func (c *Client) DeletePrefix(ctx context.Context, prefix string) ([]*DeleteError, error) {
+	if err := c.checkListPrefix(prefix); err != nil {
+		return nil, err
+	}
+
+	listPrefix := c.composeListPrefix(prefix)
+	if listPrefix == "" {
+		return nil, errDeleteWholeBucket
+	}
+
+	var listErr error
+	objects := func(yield func(minio.ObjectInfo) bool) {
+		for obj := range c.c.ListObjectsIter(ctx, c.bucket, minio.ListObjectsOptions{
+			Prefix:    listPrefix,
+			Recursive: true,
+		}) {
+			if obj.Err != nil {
+				listErr = obj.Err
+				return
+			}
+			if !yield(minio.ObjectInfo{Key: obj.Key}) {
+				return
+			}
+		}
+	}
+
+	failed, err := c.removeObjects(ctx, objects)
+	if err != nil {
+		return failed, err
+	}
+	if listErr != nil {
+		return failed, fmt.Errorf("failed to list objects: %w", s3common.ConvertError(listErr))
+	}
+	return failed, nil
+}
+
+// removeObjects 批量删除 objects，minio 内部按 1000 个文件分批发送请求
+func (c *Client) removeObjects(ctx context.Context, objects iter.Seq[minio.ObjectInfo]) ([]*DeleteError, error) {
+	results, err := c.c.RemoveObjectsWithIter(ctx, c.bucket, objects, minio.RemoveObjectsOptions{})
+	if err != nil {
+		return nil, s3common.ConvertError(err)
+	}
+
+	var failed []*DeleteError
+	for ret := range results {
+		if ret.Err == nil {
+			continue
+		}
+		failed = append(failed, &DeleteError{
+			Path: c.trimObjectName(ret.ObjectName),
+			Err:  s3common.ConvertError(ret.Err),
+		})
+	}
+
+	if err := ctx.Err(); err != nil {
+		return failed, err
+	}
+
+	return failed, nil
+}
@@ -0,0 +1,153 @@
+package s3_test
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ix64/s3-go/s3"
+	"github.com/ix64/s3-go/s3common"
+)
+
+func TestClient_DeletePrefixWholeBucket(t *testing.T) {
+	// no request is sent, guard is checked before listing
+	c, err := s3.NewClient(&s3.Config{
+		Endpoint:     "http://127.0.0.1:1",
+		Bucket:       "my-bucket",
+		BucketLookup: s3common.BucketLookupPath,
+		Region:       "us-east-1",
+		AccessKey:    "ak",
+		SecretKey:    "sk",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, prefix := range []string{"", "/"} {
+		failed, err := c.DeletePrefix(context.Background(), prefix)
+		assert.ErrorIs(t, err, s3.ErrInvalidArgument)
+		assert.Empty(t, failed)
+	}
+}
+
+func TestClient_PrefixOutOfScope(t *testing.T) {
+	// no request is sent, guard is checked before listing
+	c, err := s3.NewClient(&s3.Config{
+		Endpoint:     "http://127.0.0.1:1",
+		Bucket:       "my-bucket",
+		BucketLookup: s3common.BucketLookupPath,
+		Region:       "us-east-1",
+		AccessKey:    "ak",
+		SecretKey:    "sk",
+		Prefix:       "app",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	for _, prefix := range []string{"..", "../other", "a/../../other", "/../app-other/"} {
+		failed, err := c.DeletePrefix(ctx, prefix)
+		assert.ErrorIs(t, err, s3.ErrInvalidArgument, prefix)
+		assert.Empty(t, failed)
+
+		_, err = c.List(ctx, prefix, nil)
+		assert.ErrorIs(t, err, s3.ErrInvalidArgument, prefix)
+
+		walked := false
+		for _, err := range c.Walk(ctx, prefix) {
+			walked = true
+			assert.ErrorIs(t, err, s3.ErrInvalidArgument, prefix)
+		}
+		assert.True(t, walked, prefix)
+
+		n, err := c.CleanupIncompleteUploads(ctx, prefix, 0, nil)
+		assert.ErrorIs(t, err, s3.ErrInvalidArgument, prefix)
+		assert.Zero(t, n)
+	}
+}
+
+// listServer 只实现 bucket 位置查询、ListObjectsV2 及 Multi-Object Delete
+type listServer struct {
+	mu   sync.Mutex
+	keys []string
+}
+
+func (s *listServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	query := r.URL.Query()
+	switch {
+	case query.Has("location"):
+		_, _ = fmt.Fprint(w, `<LocationConstraint>us-east-1</LocationConstraint>`)
+	case r.Method == http.MethodGet && query.Get("list-type") == "2":
+		var contents strings.Builder
+		count := 0
+		for _, key := range s.keys {
+			if strings.HasPrefix(key, query.Get("prefix")) {
+				count++
+				_, _ = fmt.Fprintf(&contents, `<Contents><Key>%s</Key><Size>1</Size><ETag>"etag"</ETag><LastModified>2024-01-01T00:00:00.000Z</LastModified></Contents>`, key)
+			}
+		}
+		_, _ = fmt.Fprintf(w, `<ListBucketResult><Name>my-bucket</Name><Prefix>%s</Prefix><KeyCount>%d</KeyCount><MaxKeys>1000</MaxKeys><IsTruncated>false</IsTruncated>%s</ListBucketResult>`,
+			query.Get("prefix"), count, contents.String())
+	case r.Method == http.MethodPost && query.Has("delete"):
+		var req struct {
+			Objects []struct {
+				Key string `xml:"Key"`
+			} `xml:"Object"`
+		}
+		if err := xml.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		for _, obj := range req.Objects {
+			s.keys = slices.DeleteFunc(s.keys, func(key string) bool { return key == obj.Key })
+		}
+		_, _ = fmt.Fprint(w, `<DeleteResult></DeleteResult>`)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func TestClient_DeletePrefixMatch(t *testing.T) {
+	for _, tc := range []struct {
+		prefix string
+		left   []string
+	}{
+		// string prefix, siblings sharing the prefix are deleted as well
+		{prefix: "user/12", left: []string{"user/1/c.txt"}},
+		{prefix: "user/12/", left: []string{"user/1/c.txt", "user/12.txt", "user/123/b.txt"}},
+	} {
+		t.Run(tc.prefix, func(t *testing.T) {
+			srv := &listServer{keys: []string{"user/1/c.txt", "user/12.txt", "user/12/a.txt", "user/123/b.txt"}}
+			ts := httptest.NewServer(srv)
+			defer ts.Close()
+
+			c, err := s3.NewClient(&s3.Config{
+				Endpoint:     ts.URL,
+				Bucket:       "my-bucket",
+				BucketLookup: s3common.BucketLookupPath,
+				Region:       "us-east-1",
+				AccessKey:    "ak",
+				SecretKey:    "sk",
+			})
+			require.NoError(t, err)
+
+			failed, err := c.DeletePrefix(context.Background(), tc.prefix)
+			assert.NoError(t, err)
+			assert.Empty(t, failed)
+			assert.Equal(t, tc.left, srv.keys)
+		})
+	}
+}
@@ -0,0 +1,294 @@
+package s3
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
+	"github.com/minio/minio-go/v7/pkg/s3utils"
+
+	"github.com/ix64/s3-go/s3common"
+)
+
+const (
+	copyMaxSize             = 5 << 30   // 5 GiB, S3 limit of CopyObject and UploadPartCopy
+	copyDefaultPartSize     = 512 << 20 // 512 MiB
+	copyDefaultConcurrency  = 4
+	headerCopySource        = "x-amz-copy-source"
+	headerCopySourceIfMatch = "x-amz-copy-source-if-match"
+	headerChecksumAlgorithm = "x-amz-checksum-algorithm"
+	headerChecksumType      = "x-amz-checksum-type"
+	checksumTypeFullObject  = "FULL_OBJECT"
+)
+
+func (o *CopyOptions) multipartThreshold() int64 {
+	if o.MultipartThreshold <= 0 {
+		return copyMaxSize
+	}
+	return min(o.MultipartThreshold, copyMaxSize)
+}
+
+func (o *CopyOptions) partSize(size int64) int64 {
+	partSize := o.PartSize
+	if partSize <= 0 {
+		partSize = copyDefaultPartSize
+	}
+	partSize = max(partSize, multipartMinPartSize)
+
+	if minPartSize := (size + multipartMaxParts - 1) / multipartMaxParts; partSize < minPartSize {
+		partSize = minPartSize
+	}
+	return min(partSize, copyMaxSize)
+}
+
+func (o *CopyOptions) concurrency() int {
+	if o.Concurrency <= 0 {
+		return copyDefaultConcurrency
+	}
+	return o.Concurrency
+}
+
+// copyChecksum 分片复制时请求的完整对象校验和
+type copyChecksum struct {
+	// Algorithm 为 x-amz-checksum-algorithm 的取值，如 CRC64NVME
+	Algorithm string
+
+	// Value 源对象的完整对象校验和 (base64)
+	Value string
+}
+
+// header 返回携带校验和取值的请求头，如 x-amz-checksum-crc64nvme
+func (cs *copyChecksum) header() string {
+	return "x-amz-checksum-" + strings.ToLower(cs.Algorithm)
+}
+
+// value 返回 info 中与 cs 同类的完整对象校验和，不存在时返回空字符串
+func (cs *copyChecksum) value(info minio.ObjectInfo) string {
+	var v string
+	switch cs.Algorithm {
+	case "CRC64NVME":
+		v = info.ChecksumCRC64NVME
+	case "CRC32C":
+		v = info.ChecksumCRC32C
+	case "CRC32":
+		v = info.ChecksumCRC32
+	}
+	if !isFullObjectChecksum(v, info) {
+		return ""
+	}
+	return v
+}
+
+// sourceCopyChecksum 返回源对象可用于校验分片复制的完整对象校验和
+//
+// 只有 CRC 类校验和可由分片合并为完整对象校验和 (FULL_OBJECT)，SHA 类分片上传只能生成组合校验和
+func sourceCopyChecksum(src minio.ObjectInfo) *copyChecksum {
+	for _, algorithm := range []string{"CRC64NVME", "CRC32C", "CRC32"} {
+		cs := &copyChecksum{Algorithm: algorithm}
+		if cs.Value = cs.value(src); cs.Value != "" {
+			return cs
+		}
+	}
+	return nil
+}
+
+// copyMultipart 使用 UploadPartCopy 将 src 客户端的对象分片并发复制到 c，两者需为同一服务
+//
+// 分片上传不会继承源对象的元数据，需要在发起上传时显式设置，putOpts 通常由 copyPutOptions 生成；
+// 每个分片均以源对象 ETag 为条件复制。发起上传时请求与源对象同类的完整对象校验和，
+// 完成上传时提交源对象的校验和由服务端校验，完成后再比较一次，参见 verifyCopy；
+// 源对象没有完整对象 CRC 校验和时返回 ErrNotSupported，除非 CopyOptions.AllowSizeOnlyVerification
+func (c *Client) copyMultipart(ctx context.Context, srcClient *Client, src minio.ObjectInfo, srcObject, dstObject string, putOpts minio.PutObjectOptions, opts *CopyOptions) error {
+	core := minio.Core{Client: c.c}
+
+	cs := sourceCopyChecksum(src)
+	if cs == nil && !opts.AllowSizeOnlyVerification {
+		return fmt.Errorf("%w: source object has no full object crc checksum to verify multipart copy", s3common.ErrNotSupported)
+	}
+
+	completeOpts := minio.PutObjectOptions{
+		ServerSideEncryption: onlySSEC(putOpts.ServerSideEncryption),
+	}
+	if cs != nil {
+		metadata := make(map[string]string, len(putOpts.UserMetadata)+2)
+		maps.Copy(metadata, putOpts.UserMetadata)
+		metadata[headerChecksumAlgorithm] = cs.Algorithm
+		metadata[headerChecksumType] = checksumTypeFullObject
+		putOpts.UserMetadata = metadata
+
+		completeOpts.UserMetadata = map[string]string{
+			cs.header():        cs.Value,
+			headerChecksumType: checksumTypeFullObject,
+		}
+	}
+
+	uploadID, err := core.NewMultipartUpload(ctx, c.bucket, dstObject, putOpts)
+	if err != nil {
+		return fmt.Errorf("failed to initiate multipart copy: %w", s3common.ConvertError(err))
+	}
+
+	parts, err := c.copyMultipartParts(ctx, core, srcClient, src, srcObject, dstObject, uploadID, onlySSEC(putOpts.ServerSideEncryption), opts)
+	if err == nil {
+		_, err = core.CompleteMultipartUpload(ctx, c.bucket, dstObject, uploadID, parts, completeOpts)
+		if err != nil {
+			err = fmt.Errorf("failed to complete multipart copy: %w", s3common.ConvertError(err))
+		}
+	}
+	if err != nil {
+		// parts are billed until aborted
+		_ = core.AbortMultipartUpload(context.WithoutCancel(ctx), c.bucket, dstObject, uploadID)
+		return err
+	}
+
+	if err := c.verifyCopy(ctx, src, dstObject, onlySSEC(putOpts.ServerSideEncryption), cs); err != nil {
+		// do not leave a corrupted copy, unless it has replaced the source in place
+		if srcClient.cfg.Bucket != c.cfg.Bucket || srcObject != dstObject {
+			_ = c.c.RemoveObject(context.WithoutCancel(ctx), c.bucket, dstObject, minio.RemoveObjectOptions{})
+		}
+		return err
+	}
+
+	return nil
+}
+
+// copyPutOptions 按源对象的标准头、自定义元数据、存储类型及标签生成分片复制的上传参数
+func (c *Client) copyPutOptions(ctx context.Context, srcClient *Client, src minio.ObjectInfo, srcObject string, sse encrypt.ServerSide) (minio.PutObjectOptions, error) {
+	putOpts := minio.PutObjectOptions{
+		ContentType:          src.ContentType,
+		UserMetadata:         src.UserMetadata,
+		CacheControl:         src.Metadata.Get("Cache-Control"),
+		ContentDisposition:   src.Metadata.Get("Content-Disposition"),
+		ContentEncoding:      src.Metadata.Get("Content-Encoding"),
+		ContentLanguage:      src.Metadata.Get("Content-Language"),
+		Expires:              src.Expires,
+		StorageClass:         cmp.Or(src.StorageClass, src.Metadata.Get("X-Amz-Storage-Class")), // StatObject only keeps it in Metadata
+		ServerSideEncryption: sse,
+	}
+
+	if src.UserTagCount > 0 {
+		t, err := srcClient.c.GetObjectTagging(ctx, srcClient.bucket, srcObject, minio.GetObjectTaggingOptions{VersionID: src.VersionID})
+		if err != nil {
+			return putOpts, fmt.Errorf("failed to get source tags: %w", s3common.ConvertError(err))
+		}
+		putOpts.UserTags = t.ToMap()
+	}
+
+	return putOpts, nil
+}
+
+func (c *Client) copyMultipartParts(
+	ctx context.Context,
+	core minio.Core,
+	srcClient *Client,
+	src minio.ObjectInfo,
+	srcObject string,
+	dstObject string,
+	uploadID string,
+	dstSSEC encrypt.ServerSide,
+	opts *CopyOptions,
+) ([]minio.CompletePart, error) {
+	partSize := opts.partSize(src.Size)
+	partCount := int((src.Size + partSize - 1) / partSize)
+
+	header := http.Header{}
+
+	// copy source is resolved by server, always use the real bucket and pin the version stat
+	copySource := s3utils.EncodePath(srcClient.cfg.Bucket + "/" + srcObject)
+	if src.VersionID != "" && src.VersionID != "null" {
+		copySource += "?versionId=" + url.QueryEscape(src.VersionID)
+	}
+	header.Set(headerCopySource, copySource)
+	header.Set(headerCopySourceIfMatch, src.ETag)
+
+	if sse := srcClient.readSSE(opts.SourceEncryption); sse != nil {
+		encrypt.SSECopy(sse).Marshal(header)
+	}
+	// SSE-C key of destination is required by every part
+	if dstSSEC != nil {
+		dstSSEC.Marshal(header)
+	}
+
+	metadata := make(map[string]string, len(header))
+	for k := range header {
+		metadata[k] = header.Get(k)
+	}
+
+	partNumbers := make([]int, partCount)
+	for i := range partNumbers {
+		partNumbers[i] = i + 1
+	}
+
+	var (
+		mu     sync.Mutex
+		copied int64
+	)
+
+	// each task writes its own element, lock is required by progress only
+	parts := make([]minio.CompletePart, partCount)
+	err := runParallel(ctx, partNumbers, opts.concurrency(), func(ctx context.Context, partNumber int) error {
+		offset := int64(partNumber-1) * partSize
+		length := min(partSize, src.Size-offset)
+
+		part, err := core.CopyObjectPart(ctx, srcClient.cfg.Bucket, srcObject, c.bucket, dstObject, uploadID, partNumber, offset, length, metadata)
+		if err != nil {
+			return fmt.Errorf("failed to copy part %d: %w", partNumber, s3common.ConvertError(err))
+		}
+		if part.ETag == "" {
+			return fmt.Errorf("failed to copy part %d: %w", partNumber, errors.New("empty etag returned"))
+		}
+
+		parts[partNumber-1] = minio.CompletePart{PartNumber: partNumber, ETag: part.ETag}
+
+		if opts.Progress != nil {
+			mu.Lock()
+			defer mu.Unlock()
+			copied += length
+			opts.Progress(copied, src.Size)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return parts, nil
+}
+
+// verifyCopy 校验分片复制结果的大小及完整对象校验和，cs 为 nil 时只校验大小
+//
+// 服务端忽略校验和请求头时目标对象没有对应的校验和，返回 ErrNotSupported
+func (c *Client) verifyCopy(ctx context.Context, src minio.ObjectInfo, dstObject string, sse encrypt.ServerSide, cs *copyChecksum) error {
+	dst, err := c.c.StatObject(ctx, c.bucket, dstObject, minio.StatObjectOptions{
+		ServerSideEncryption: sse,
+		Checksum:             true,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to stat copied object: %w", s3common.ConvertError(err))
+	}
+
+	if dst.Size != src.Size {
+		return fmt.Errorf("%w: size mismatch: expect %d, got %d", s3common.ErrChecksumMismatch, src.Size, dst.Size)
+	}
+
+	if cs == nil {
+		return nil
+	}
+
+	got := cs.value(dst)
+	if got == "" {
+		return fmt.Errorf("%w: server did not store full object %s checksum of copied object", s3common.ErrNotSupported, cs.Algorithm)
+	}
+	if got != cs.Value {
+		return fmt.Errorf("%w: %s mismatch: expect %s, got %s", s3common.ErrChecksumMismatch, strings.ToLower(cs.Algorithm), cs.Value, got)
+	}
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package s3_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ix64/s3-go/s3"
+	"github.com/ix64/s3-go/s3common"
+)
+
+// copyServer 只实现 bucket 位置查询及分片复制所需的接口，完成上传后目标对象使用 complete 请求提交的校验和
+type copyServer struct {
+	checksum string // full object crc64nvme of source, empty for none
+	ignore   bool   // do not store checksum of completed object, like servers without checksum support
+
+	mu             sync.Mutex
+	initiateHeader http.Header
+	completeHeader http.Header
+	completed      bool
+}
+
+func (s *copyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	query := r.URL.Query()
+	switch {
+	case query.Has("location"):
+		_, _ = fmt.Fprint(w, `<LocationConstraint>us-east-1</LocationConstraint>`)
+	case r.Method == http.MethodHead:
+		checksum := s.checksum
+		if strings.HasSuffix(r.URL.Path, "/dst.bin") {
+			if !s.completed {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			checksum = s.completeHeader.Get("x-amz-checksum-crc64nvme")
+			if s.ignore {
+				checksum = ""
+			}
+		}
+		w.Header().Set("Content-Length", "12")
+		w.Header().Set("ETag", `"etag"`)
+		w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+		if checksum != "" {
+			w.Header().Set("x-amz-checksum-crc64nvme", checksum)
+			w.Header().Set("x-amz-checksum-type", "FULL_OBJECT")
+		}
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		s.initiateHeader = r.Header.Clone()
+		_, _ = fmt.Fprint(w, `<InitiateMultipartUploadResult><UploadId>upload-id</UploadId></InitiateMultipartUploadResult>`)
+	case r.Method == http.MethodPut && query.Has("partNumber"):
+		_, _ = fmt.Fprintf(w, `<CopyPartResult><ETag>"part-%s"</ETag></CopyPartResult>`, query.Get("partNumber"))
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		s.completeHeader = r.Header.Clone()
+		s.completed = true
+		_, _ = fmt.Fprint(w, `<CompleteMultipartUploadResult><Bucket>my-bucket</Bucket><Key>dst.bin</Key><ETag>"etag-1"</ETag></CompleteMultipartUploadResult>`)
+	case r.Method == http.MethodDelete:
+		s.completed = false
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func TestClient_CopyMultipartChecksum(t *testing.T) {
+	newClient := func(srv *httptest.Server) *s3.Client {
+		c, err := s3.NewClient(&s3.Config{
+			Endpoint:     srv.URL,
+			Bucket:       "my-bucket",
+			BucketLookup: s3common.BucketLookupPath,
+			Region:       "us-east-1",
+			AccessKey:    "ak",
+			SecretKey:    "sk",
+		})
+		require.NoError(t, err)
+		return c
+	}
+
+	ctx := context.Background()
+
+	t.Run("FullObject", func(t *testing.T) {
+		cs := &copyServer{checksum: "AAAAAAAAAAA="}
+		srv := httptest.NewServer(cs)
+		defer srv.Close()
+
+		err := newClient(srv).CopyWithOptions(ctx, "src.bin", "dst.bin", &s3.CopyOptions{MultipartThreshold: 1})
+		require.NoError(t, err)
+
+		// full object checksum is requested on initiation and verified by server on completion
+		assert.Equal(t, "CRC64NVME", cs.initiateHeader.Get("x-amz-checksum-algorithm"))
+		assert.Equal(t, "FULL_OBJECT", cs.initiateHeader.Get("x-amz-checksum-type"))
+		assert.Equal(t, "AAAAAAAAAAA=", cs.completeHeader.Get("x-amz-checksum-crc64nvme"))
+		assert.Equal(t, "FULL_OBJECT", cs.completeHeader.Get("x-amz-checksum-type"))
+	})
+
+	t.Run("NoSourceChecksum", func(t *testing.T) {
+		cs := &copyServer{}
+		srv := httptest.NewServer(cs)
+		defer srv.Close()
+
+		err := newClient(srv).CopyWithOptions(ctx, "src.bin", "dst.bin", &s3.CopyOptions{MultipartThreshold: 1})
+		assert.ErrorIs(t, err, s3.ErrNotSupported)
+		assert.Nil(t, cs.initiateHeader)
+
+		err = newClient(srv).CopyWithOptions(ctx, "src.bin", "dst.bin", &s3.CopyOptions{
+			MultipartThreshold:        1,
+			AllowSizeOnlyVerification: true,
+		})
+		assert.NoError(t, err)
+		assert.Empty(t, cs.initiateHeader.Get("x-amz-checksum-algorithm"))
+	})
+
+	t.Run("ServerIgnoresChecksum", func(t *testing.T) {
+		cs := &copyServer{checksum: "AAAAAAAAAAA=", ignore: true}
+		srv := httptest.NewServer(cs)
+		defer srv.Close()
+
+		err := newClient(srv).CopyWithOptions(ctx, "src.bin", "dst.bin", &s3.CopyOptions{MultipartThreshold: 1})
+		assert.ErrorIs(t, err, s3.ErrNotSupported)
+		assert.False(t, cs.completed, "unverified copy should be removed")
+	})
+}
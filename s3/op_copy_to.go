@@ -0,0 +1,148 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/minio/minio-go/v7"
+
+	"github.com/ix64/s3-go/s3common"
+)
+
+// CopyTo 复制文件到另一个客户端，参见 CopyToWithOptions
+func (c *Client) CopyTo(ctx context.Context, srcPath string, dst *Client, dstPath string) error {
+	return c.CopyToWithOptions(ctx, srcPath, dst, dstPath, nil)
+}
+
+// CopyToWithOptions 按指定选项复制文件到另一个客户端，可跨 bucket 及供应商
+//
+// 两个客户端的 Endpoint、Region 及 AccessKey 相同时使用服务端复制，
+// 否则流式下载后上传，保留 Content-Type 等标准头、自定义元数据及标签，
+// 存储类型仅在 dst 与源位于同一 Endpoint 时保留，不同供应商的存储类型名称不通用。
+// opts.Encryption 未设置时使用 dst 的 Config.Encryption
+func (c *Client) CopyToWithOptions(ctx context.Context, srcPath string, dst *Client, dstPath string, opts *CopyOptions) error {
+	if opts == nil {
+		opts = &CopyOptions{}
+	}
+
+	info, err := c.StatWithOptions(ctx, srcPath, &StatOptions{
+		VersionID:  opts.SourceVersionID,
+		Encryption: opts.SourceEncryption,
+	})
+	if err != nil {
+		return err
+	}
+
+	srcObject, dstObject := c.composeObjectName(srcPath), dst.composeObjectName(dstPath)
+
+	same, err := c.sameService(ctx, dst)
+	if err != nil {
+		return err
+	}
+	if same {
+		return dst.copyObject(ctx, c, info, srcObject, dstObject, opts)
+	}
+	return dst.copyStream(ctx, c, info, srcObject, dstObject, opts)
+}
+
+// MoveTo 移动文件到另一个客户端（复制后删除源文件），参见 MoveToWithOptions
+func (c *Client) MoveTo(ctx context.Context, srcPath string, dst *Client, dstPath string) error {
+	return c.MoveToWithOptions(ctx, srcPath, dst, dstPath, nil)
+}
+
+// MoveToWithOptions 按指定选项复制文件到另一个客户端后删除源文件，
+// 设置 opts.SourceVersionID 时删除的是该版本
+func (c *Client) MoveToWithOptions(ctx context.Context, srcPath string, dst *Client, dstPath string, opts *CopyOptions) error {
+	if opts == nil {
+		opts = &CopyOptions{}
+	}
+
+	if err := c.CopyToWithOptions(ctx, srcPath, dst, dstPath, opts); err != nil {
+		return err
+	}
+	return c.DeleteWithOptions(ctx, srcPath, &DeleteOptions{VersionID: opts.SourceVersionID})
+}
+
+// sameService 两个客户端访问同一服务且使用同一身份时，可以互相服务端复制
+//
+// 比较身份需要获取凭证，临时凭证过期时会随 ctx 发起刷新请求
+func (c *Client) sameService(ctx context.Context, dst *Client) (bool, error) {
+	if c == dst {
+		return true, nil
+	}
+
+	if c.endpoint.Scheme != dst.endpoint.Scheme || c.endpoint.Host != dst.endpoint.Host || c.region != dst.region {
+		return false, nil
+	}
+
+	if c.creds == dst.creds {
+		return true, nil
+	}
+
+	cc := s3common.NewCredContext(ctx)
+	a, err := c.creds.GetWithContext(cc)
+	if err != nil {
+		return false, fmt.Errorf("%w: failed to get source credentials: %w", s3common.ErrInvalidCredentials, err)
+	}
+	b, err := dst.creds.GetWithContext(cc)
+	if err != nil {
+		return false, fmt.Errorf("%w: failed to get destination credentials: %w", s3common.ErrInvalidCredentials, err)
+	}
+	return a.AccessKeyID != "" && a.AccessKeyID == b.AccessKeyID, nil
+}
+
+// copyStream 从 src 客户端流式下载对象并上传到 c
+func (c *Client) copyStream(ctx context.Context, srcClient *Client, src minio.ObjectInfo, srcObject, dstObject string, opts *CopyOptions) error {
+	getOpts := minio.GetObjectOptions{
+		ServerSideEncryption: srcClient.readSSE(opts.SourceEncryption),
+		VersionID:            opts.SourceVersionID,
+	}
+	// make sure object is not changed since stat
+	if err := getOpts.SetMatchETag(src.ETag); err != nil {
+		return err
+	}
+
+	putOpts, err := c.copyPutOptions(ctx, srcClient, src, srcObject, c.writeSSE(opts.Encryption))
+	if err != nil {
+		return err
+	}
+	putOpts.PartSize = uint64(max(opts.PartSize, 0))
+	putOpts.NumThreads = uint(max(opts.Concurrency, 0))
+
+	// storage classes differ between providers, e.g. "IA" of OSS and "STANDARD_IA" of S3
+	if srcClient.endpoint.Host != c.endpoint.Host {
+		putOpts.StorageClass = ""
+	}
+
+	obj, err := srcClient.c.GetObject(ctx, srcClient.bucket, srcObject, getOpts)
+	if err != nil {
+		return s3common.ConvertError(err)
+	}
+	defer obj.Close()
+
+	var r io.Reader = objectReader{obj}
+	if opts.Progress != nil {
+		r = &progressReader{r: r, total: src.Size, fn: opts.Progress}
+	}
+
+	_, err = c.c.PutObject(ctx, c.bucket, dstObject, r, src.Size, putOpts)
+	return s3common.ConvertError(err)
+}
+
+// progressReader 读取时回调已读取的字节数
+type progressReader struct {
+	r     io.Reader
+	read  int64
+	total int64
+	fn    func(copied, total int64)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.read += int64(n)
+		p.fn(p.read, p.total)
+	}
+	return n, err
+}
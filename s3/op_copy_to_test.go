@@ -0,0 +1,164 @@
+package s3_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ix64/s3-go/s3"
+	"github.com/ix64/s3-go/s3common"
+)
+
+func TestClient_SameService(t *testing.T) {
+	ctx := context.Background()
+
+	newClient := func(endpoint, accessKey string) *s3.Client {
+		c, err := s3.NewClient(&s3.Config{
+			Endpoint:     endpoint,
+			Bucket:       "my-bucket",
+			BucketLookup: s3common.BucketLookupPath,
+			Region:       "us-east-1",
+			AccessKey:    accessKey,
+			SecretKey:    "sk",
+		})
+		require.NoError(t, err)
+		return c
+	}
+
+	src := newClient("https://s3.example.com", "ak")
+
+	for name, tc := range map[string]struct {
+		dst  *s3.Client
+		same bool
+	}{
+		"Self":           {src, true},
+		"SameAccessKey":  {newClient("https://s3.example.com", "ak"), true},
+		"OtherAccessKey": {newClient("https://s3.example.com", "ak2"), false},
+		"OtherEndpoint":  {newClient("https://oss.example.com", "ak"), false},
+		"OtherScheme":    {newClient("http://s3.example.com", "ak"), false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			same, err := src.SameService(ctx, tc.dst)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.same, same)
+		})
+	}
+}
+
+// streamServer 保存以路径为键的对象，只实现 bucket 位置查询、HEAD、GET、PUT 及 DELETE
+type streamServer struct {
+	mu      sync.Mutex
+	objects map[string]http.Header
+	deleted []string
+}
+
+func (s *streamServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	query := r.URL.Query()
+	switch {
+	case query.Has("location"):
+		_, _ = fmt.Fprint(w, `<LocationConstraint>us-east-1</LocationConstraint>`)
+	case r.Method == http.MethodHead || r.Method == http.MethodGet:
+		header, ok := s.objects[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = fmt.Fprint(w, `<Error><Code>NoSuchKey</Code><Message>The specified key does not exist.</Message></Error>`)
+			return
+		}
+		for k, v := range header {
+			w.Header()[k] = v
+		}
+		w.Header().Set("Content-Length", "1")
+		w.Header().Set("ETag", `"etag"`)
+		w.Header().Set("Last-Modified", "Mon, 01 Jan 2024 00:00:00 GMT")
+		if r.Method == http.MethodGet {
+			_, _ = fmt.Fprint(w, "a")
+		}
+	case r.Method == http.MethodPut:
+		_, _ = io.Copy(io.Discard, r.Body)
+		s.objects[r.URL.Path] = r.Header.Clone()
+		w.Header().Set("ETag", `"etag"`)
+	case r.Method == http.MethodDelete:
+		s.deleted = append(s.deleted, r.URL.Path+"?"+r.URL.RawQuery)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func TestClient_CopyToStorageClass(t *testing.T) {
+	ctx := context.Background()
+
+	newClient := func(endpoint, accessKey string) *s3.Client {
+		c, err := s3.NewClient(&s3.Config{
+			Endpoint:     endpoint,
+			Bucket:       "my-bucket",
+			BucketLookup: s3common.BucketLookupPath,
+			Region:       "us-east-1",
+			AccessKey:    accessKey,
+			SecretKey:    "sk",
+		})
+		require.NoError(t, err)
+		return c
+	}
+
+	newServer := func() (*streamServer, *httptest.Server) {
+		srv := &streamServer{objects: map[string]http.Header{
+			"/my-bucket/a.txt": {
+				"Content-Type":        {"text/plain"},
+				"X-Amz-Storage-Class": {"STANDARD_IA"},
+				"X-Amz-Meta-Owner":    {"unit-test"},
+			},
+		}}
+		ts := httptest.NewServer(srv)
+		t.Cleanup(ts.Close)
+		return srv, ts
+	}
+
+	t.Run("SameEndpoint", func(t *testing.T) {
+		// other access key, streamed instead of copied by server
+		srv, ts := newServer()
+		src, dst := newClient(ts.URL, "ak"), newClient(ts.URL, "ak2")
+
+		assert.NoError(t, src.CopyTo(ctx, "a.txt", dst, "b.txt"))
+
+		header := srv.objects["/my-bucket/b.txt"]
+		require.NotNil(t, header)
+		assert.Equal(t, "STANDARD_IA", header.Get("X-Amz-Storage-Class"))
+		assert.Equal(t, "text/plain", header.Get("Content-Type"))
+		assert.Equal(t, "unit-test", header.Get("X-Amz-Meta-Owner"))
+	})
+
+	t.Run("OtherEndpoint", func(t *testing.T) {
+		_, srcTS := newServer()
+		dstSrv, dstTS := newServer()
+		src, dst := newClient(srcTS.URL, "ak"), newClient(dstTS.URL, "ak")
+
+		assert.NoError(t, src.CopyTo(ctx, "a.txt", dst, "b.txt"))
+
+		header := dstSrv.objects["/my-bucket/b.txt"]
+		require.NotNil(t, header)
+		assert.Empty(t, header.Get("X-Amz-Storage-Class"))
+		assert.Equal(t, "unit-test", header.Get("X-Amz-Meta-Owner"))
+	})
+
+	t.Run("MoveToWithOptions", func(t *testing.T) {
+		srv, ts := newServer()
+		src, dst := newClient(ts.URL, "ak"), newClient(ts.URL, "ak2")
+
+		err := src.MoveToWithOptions(ctx, "a.txt", dst, "b.txt", &s3.CopyOptions{SourceVersionID: "v1"})
+		assert.NoError(t, err)
+
+		assert.Equal(t, "STANDARD_IA", srv.objects["/my-bucket/b.txt"].Get("X-Amz-Storage-Class"))
+		assert.Equal(t, []string{"/my-bucket/a.txt?versionId=v1"}, srv.deleted)
+	})
+}
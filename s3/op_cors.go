@@ -0,0 +1,148 @@
+package s3
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/minio/minio-go/v7/pkg/cors"
+
+	"github.com/ix64/s3-go/s3common"
+	"github.com/ix64/s3-go/s3up"
+)
+
+const defaultUploadCORSRuleID = "s3-go-upload"
+
+// CORSRule bucket 的 CORS 规则
+type CORSRule struct {
+	ID             string
+	AllowedOrigins []string
+	AllowedMethods []string
+	AllowedHeaders []string
+	ExposeHeaders  []string
+	MaxAgeSeconds  int
+}
+
+type UploadCORSOptions struct {
+	// required, origins of pages uploading directly, e.g. https://app.example.com, "*" allows any origin
+	AllowedOrigins []string
+
+	// optional, default to s3-go-upload, use different ids for clients with different upload generator config
+	ID string
+
+	// optional, default to 3600
+	MaxAgeSeconds int
+}
+
+// GetCORSRules 获取 bucket 的全部 CORS 规则，未配置时返回空
+func (c *Client) GetCORSRules(ctx context.Context) ([]CORSRule, error) {
+	config, err := c.c.GetBucketCors(ctx, c.bucket)
+	if err != nil {
+		return nil, s3common.ConvertError(err)
+	}
+	if config == nil {
+		return nil, nil // NoSuchCORSConfiguration
+	}
+
+	ret := make([]CORSRule, 0, len(config.CORSRules))
+	for _, rule := range config.CORSRules {
+		ret = append(ret, CORSRule{
+			ID:             rule.ID,
+			AllowedOrigins: rule.AllowedOrigin,
+			AllowedMethods: rule.AllowedMethod,
+			AllowedHeaders: rule.AllowedHeader,
+			ExposeHeaders:  rule.ExposeHeader,
+			MaxAgeSeconds:  rule.MaxAgeSeconds,
+		})
+	}
+	return ret, nil
+}
+
+// UploadCORSRule 根据上传链接生成器的配置，返回浏览器直传所需的 CORS 规则
+//
+// 上传链接生成器需实现 s3up.CORSGenerator，否则返回 ErrNotSupported
+func (c *Client) UploadCORSRule(opts *UploadCORSOptions) (*CORSRule, error) {
+	if opts == nil || len(opts.AllowedOrigins) == 0 {
+		return nil, fmt.Errorf("%w: allowed origins is required", s3common.ErrInvalidArgument)
+	}
+
+	g, ok := c.upload.(s3up.CORSGenerator)
+	if !ok {
+		return nil, fmt.Errorf("%w: upload generator does not provide cors requirement", s3common.ErrNotSupported)
+	}
+	req := g.CORSRequirement()
+
+	return &CORSRule{
+		ID:             cmp.Or(opts.ID, defaultUploadCORSRuleID),
+		AllowedOrigins: opts.AllowedOrigins,
+		AllowedMethods: req.Methods,
+		AllowedHeaders: req.AllowedHeaders,
+		ExposeHeaders:  req.ExposeHeaders,
+		MaxAgeSeconds:  cmp.Or(opts.MaxAgeSeconds, 3600),
+	}, nil
+}
+
+// EnsureUploadCORS 按 ID 新增或更新浏览器直传所需的 CORS 规则，其他规则保持不变
+//
+// 规则已经一致时不会写入 bucket，可在每次启动时调用。
+// S3 不支持条件写入 CORS 配置，多个服务同时更新时后写入者生效
+func (c *Client) EnsureUploadCORS(ctx context.Context, opts *UploadCORSOptions) error {
+	rule, err := c.UploadCORSRule(opts)
+	if err != nil {
+		return err
+	}
+
+	rules, err := c.GetCORSRules(ctx)
+	if err != nil {
+		return err
+	}
+
+	i := slices.IndexFunc(rules, func(r CORSRule) bool { return r.ID == rule.ID })
+	if i >= 0 {
+		if rules[i].equal(rule) {
+			return nil
+		}
+		rules[i] = *rule
+	} else {
+		rules = append(rules, *rule)
+	}
+
+	config := make([]cors.Rule, 0, len(rules))
+	for _, r := range rules {
+		config = append(config, cors.Rule{
+			ID:            r.ID,
+			AllowedOrigin: r.AllowedOrigins,
+			AllowedMethod: r.AllowedMethods,
+			AllowedHeader: r.AllowedHeaders,
+			ExposeHeader:  r.ExposeHeaders,
+			MaxAgeSeconds: r.MaxAgeSeconds,
+		})
+	}
+
+	err = c.c.SetBucketCors(ctx, c.bucket, cors.NewConfig(config))
+	return s3common.ConvertError(err)
+}
+
+// equal 比较两条规则，忽略顺序及 header、method 的大小写
+func (r *CORSRule) equal(o *CORSRule) bool {
+	return r.ID == o.ID &&
+		r.MaxAgeSeconds == o.MaxAgeSeconds &&
+		slices.Equal(normalizeCORSValues(r.AllowedOrigins, false), normalizeCORSValues(o.AllowedOrigins, false)) &&
+		slices.Equal(normalizeCORSValues(r.AllowedMethods, true), normalizeCORSValues(o.AllowedMethods, true)) &&
+		slices.Equal(normalizeCORSValues(r.AllowedHeaders, true), normalizeCORSValues(o.AllowedHeaders, true)) &&
+		slices.Equal(normalizeCORSValues(r.ExposeHeaders, true), normalizeCORSValues(o.ExposeHeaders, true))
+}
+
+func normalizeCORSValues(values []string, foldCase bool) []string {
+	ret := make([]string, 0, len(values))
+	for _, v := range values {
+		if foldCase {
+			v = strings.ToLower(v)
+		}
+		ret = append(ret, v)
+	}
+	slices.Sort(ret)
+	return slices.Compact(ret)
+}
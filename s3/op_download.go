@@ -0,0 +1,142 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
+
+	"github.com/ix64/s3-go/s3common"
+)
+
+type DownloadOptions struct {
+	// optional, read from Offset, or Length bytes from Offset if Length > 0
+	Offset int64
+	Length int64
+
+	// optional, read last SuffixLength bytes, can not be used with Offset and Length
+	SuffixLength int64
+
+	// optional, return ErrPreconditionFailed if object ETag does not match
+	IfMatch string
+
+	// optional, return ErrNotModified if object ETag matches
+	IfNoneMatch string
+
+	// optional, return ErrNotModified if object is not modified since
+	IfModifiedSince time.Time
+
+	// optional, return ErrPreconditionFailed if object is modified since
+	IfUnmodifiedSince time.Time
+
+	// optional, read a specific version instead of the latest one
+	VersionID string
+
+	// optional, SSE-C key of object, default to Config.Encryption
+	Encryption encrypt.ServerSide
+}
+
+func (o *DownloadOptions) toGetObjectOptions() (minio.GetObjectOptions, error) {
+	ret := minio.GetObjectOptions{}
+
+	switch {
+	case o.Offset < 0 || o.Length < 0 || o.SuffixLength < 0:
+		return ret, fmt.Errorf("%w: offset, length and suffix length can not be negative", s3common.ErrInvalidArgument)
+	case o.SuffixLength > 0:
+		if o.Offset != 0 || o.Length != 0 {
+			return ret, fmt.Errorf("%w: suffix length can not be used with offset and length", s3common.ErrInvalidArgument)
+		}
+		if err := ret.SetRange(0, -o.SuffixLength); err != nil {
+			return ret, err
+		}
+	case o.Length > 0:
+		if err := ret.SetRange(o.Offset, o.Offset+o.Length-1); err != nil {
+			return ret, err
+		}
+	case o.Offset > 0:
+		if err := ret.SetRange(o.Offset, 0); err != nil {
+			return ret, err
+		}
+	}
+
+	if o.IfMatch != "" {
+		if err := ret.SetMatchETag(trimETag(o.IfMatch)); err != nil {
+			return ret, err
+		}
+	}
+	if o.IfNoneMatch != "" {
+		if err := ret.SetMatchETagExcept(trimETag(o.IfNoneMatch)); err != nil {
+			return ret, err
+		}
+	}
+	if !o.IfModifiedSince.IsZero() {
+		if err := ret.SetModified(o.IfModifiedSince); err != nil {
+			return ret, err
+		}
+	}
+	if !o.IfUnmodifiedSince.IsZero() {
+		if err := ret.SetUnmodified(o.IfUnmodifiedSince); err != nil {
+			return ret, err
+		}
+	}
+
+	return ret, nil
+}
+
+type DownloadResult struct {
+	Body io.ReadCloser
+
+	// Info 对象信息，范围请求时 Size 为返回内容的大小
+	Info minio.ObjectInfo
+
+	// ContentRange 范围请求时的 "Content-Range" 响应头，例如 "bytes 0-99/1000"
+	ContentRange string
+
+	// TotalSize 对象的完整大小
+	TotalSize int64
+}
+
+// DownloadWithOptions 按范围或条件获取文件内容
+//
+// 与 Download 不同，请求会立即发出，条件不满足时返回 ErrNotModified 或 ErrPreconditionFailed
+func (c *Client) DownloadWithOptions(ctx context.Context, remotePath string, opts *DownloadOptions) (*DownloadResult, error) {
+	if opts == nil {
+		opts = &DownloadOptions{}
+	}
+
+	getOpts, err := opts.toGetObjectOptions()
+	if err != nil {
+		return nil, err
+	}
+	getOpts.ServerSideEncryption = c.readSSE(opts.Encryption)
+	getOpts.VersionID = opts.VersionID
+
+	core := minio.Core{Client: c.c}
+	body, info, header, err := core.GetObject(ctx, c.bucket, c.composeObjectName(remotePath), getOpts)
+	if err != nil {
+		return nil, s3common.ConvertError(err)
+	}
+
+	info.Key = c.trimObjectName(info.Key)
+
+	ret := &DownloadResult{
+		Body:         body,
+		Info:         info,
+		ContentRange: header.Get("Content-Range"),
+		TotalSize:    info.Size,
+	}
+
+	// Content-Range: bytes <start>-<end>/<total>
+	if _, total, ok := strings.Cut(ret.ContentRange, "/"); ok && total != "*" {
+		if size, err := strconv.ParseInt(total, 10, 64); err == nil {
+			ret.TotalSize = size
+		}
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,293 @@
+package s3
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
+
+	"github.com/ix64/s3-go/s3common"
+)
+
+const (
+	downloadDefaultPartSize = 16 << 20 // 16 MiB
+	downloadDefaultWorkers  = 4
+
+	// downloadTempSuffix 下载中的临时文件后缀，断点信息保存在 downloadTempSuffix + ".json"
+	downloadTempSuffix = ".s3download"
+)
+
+type DownloadFileOptions struct {
+	// optional, size of each range request, default to 16 MiB
+	PartSize int64
+
+	// optional, number of ranges downloaded concurrently, default to 4
+	Concurrency int
+
+	// optional, download a specific version instead of the latest one
+	VersionID string
+
+	// optional, SSE-C key of object, default to Config.Encryption
+	Encryption encrypt.ServerSide
+}
+
+func (o *DownloadFileOptions) partSize() int64 {
+	if o.PartSize <= 0 {
+		return downloadDefaultPartSize
+	}
+	return o.PartSize
+}
+
+func (o *DownloadFileOptions) concurrency() int {
+	if o.Concurrency <= 0 {
+		return downloadDefaultWorkers
+	}
+	return o.Concurrency
+}
+
+// downloadCheckpoint 下载断点信息，对象 ETag 或大小变化时重新下载
+type downloadCheckpoint struct {
+	ETag     string `json:"etag"`
+	Size     int64  `json:"size"`
+	PartSize int64  `json:"part_size"`
+	Done     []int  `json:"done"`
+}
+
+// DownloadFileWithOptions 分段并发下载文件到指定的本地路径
+//
+// 下载过程中写入 localPath + ".s3download" 临时文件，中断后再次调用会跳过已完成的分段，
+// 全部完成后校验大小、ETag 及校验和，再重命名为 localPath
+func (c *Client) DownloadFileWithOptions(ctx context.Context, remotePath string, localPath string, opts *DownloadFileOptions) error {
+	if opts == nil {
+		opts = &DownloadFileOptions{}
+	}
+
+	info, err := c.StatWithOptions(ctx, remotePath, &StatOptions{
+		VersionID:  opts.VersionID,
+		Encryption: opts.Encryption,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(localPath), 0o755); err != nil {
+		return err
+	}
+
+	tempPath := localPath + downloadTempSuffix
+	checkpointPath := tempPath + ".json"
+
+	cp := loadDownloadCheckpoint(checkpointPath)
+	if cp == nil || cp.ETag != info.ETag || cp.Size != info.Size || cp.PartSize != opts.partSize() {
+		cp = &downloadCheckpoint{
+			ETag:     info.ETag,
+			Size:     info.Size,
+			PartSize: opts.partSize(),
+		}
+		// object changed, previous content is useless
+		if err := os.Remove(tempPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	f, err := os.OpenFile(tempPath, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := f.Truncate(info.Size); err != nil {
+		return err
+	}
+
+	partCount := int((info.Size + cp.PartSize - 1) / cp.PartSize)
+	pending := make([]int, 0, partCount)
+	for i := range partCount {
+		if !slices.Contains(cp.Done, i) {
+			pending = append(pending, i)
+		}
+	}
+
+	var mu sync.Mutex
+	err = runParallel(ctx, pending, opts.concurrency(), func(ctx context.Context, i int) error {
+		offset := int64(i) * cp.PartSize
+		length := min(cp.PartSize, info.Size-offset)
+
+		rangeOpts := &DownloadOptions{
+			Offset:     offset,
+			Length:     length,
+			IfMatch:    info.ETag, // make sure object is not changed during downloading
+			VersionID:  opts.VersionID,
+			Encryption: opts.Encryption,
+		}
+		if err := c.downloadRange(ctx, remotePath, rangeOpts, f); err != nil {
+			return fmt.Errorf("failed to download range %d-%d: %w", offset, offset+length-1, err)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		// flush content before recording progress, otherwise a crash may leave holes
+		if err := f.Sync(); err != nil {
+			return err
+		}
+		cp.Done = append(cp.Done, i)
+		return saveDownloadCheckpoint(checkpointPath, cp)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := verifyDownloadedFile(f, info); err != nil {
+		// content is corrupted, start over next time
+		_ = f.Close()
+		_ = os.Remove(tempPath)
+		_ = os.Remove(checkpointPath)
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tempPath, localPath); err != nil {
+		return err
+	}
+
+	return os.Remove(checkpointPath)
+}
+
+// downloadRange 下载 opts 指定的范围，写入 w 的相同偏移处
+func (c *Client) downloadRange(ctx context.Context, remotePath string, opts *DownloadOptions, w io.WriterAt) error {
+	ret, err := c.DownloadWithOptions(ctx, remotePath, opts)
+	if err != nil {
+		return err
+	}
+	defer ret.Body.Close()
+
+	n, err := io.Copy(io.NewOffsetWriter(w, opts.Offset), ret.Body)
+	if err != nil {
+		return err
+	}
+	if n != opts.Length {
+		return fmt.Errorf("%w: unexpected range size: expect %d, got %d", s3common.ErrChecksumMismatch, opts.Length, n)
+	}
+	return nil
+}
+
+func loadDownloadCheckpoint(name string) *downloadCheckpoint {
+	buf, err := os.ReadFile(name)
+	if err != nil {
+		return nil
+	}
+
+	cp := &downloadCheckpoint{}
+	if err := json.Unmarshal(buf, cp); err != nil {
+		return nil // corrupted checkpoint, download again
+	}
+	return cp
+}
+
+func saveDownloadCheckpoint(name string, cp *downloadCheckpoint) error {
+	buf, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+
+	// write to temp file then rename, to avoid corrupted checkpoint on crash
+	if err := os.WriteFile(name+".tmp", buf, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(name+".tmp", name)
+}
+
+// verifyDownloadedFile 校验下载的文件大小、ETag 及校验和
+//
+// ETag 仅在为内容 MD5 时校验（分片上传或服务端加密的 ETag 不是 MD5），
+// 校验和仅在服务端返回完整对象校验和时校验（分片上传的组合校验和以 "-N" 结尾）
+func verifyDownloadedFile(f *os.File, info minio.ObjectInfo) error {
+	stat, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if stat.Size() != info.Size {
+		return fmt.Errorf("%w: size mismatch: expect %d, got %d", s3common.ErrChecksumMismatch, info.Size, stat.Size())
+	}
+
+	type verifier struct {
+		name   string
+		hash   hash.Hash
+		expect string
+		encode func([]byte) string
+	}
+
+	var verifiers []verifier
+
+	if etag := trimETag(info.ETag); isMD5ETag(etag, info) {
+		verifiers = append(verifiers, verifier{"etag", md5.New(), strings.ToLower(etag), hex.EncodeToString})
+	}
+	if isFullObjectChecksum(info.ChecksumSHA256, info) {
+		verifiers = append(verifiers, verifier{"sha256", sha256.New(), info.ChecksumSHA256, base64.StdEncoding.EncodeToString})
+	}
+	if isFullObjectChecksum(info.ChecksumCRC32C, info) {
+		verifiers = append(verifiers, verifier{"crc32c", crc32.New(crc32.MakeTable(crc32.Castagnoli)), info.ChecksumCRC32C, base64.StdEncoding.EncodeToString})
+	}
+	if isFullObjectChecksum(info.ChecksumCRC32, info) {
+		verifiers = append(verifiers, verifier{"crc32", crc32.NewIEEE(), info.ChecksumCRC32, base64.StdEncoding.EncodeToString})
+	}
+
+	if len(verifiers) == 0 {
+		return nil
+	}
+
+	writers := make([]io.Writer, 0, len(verifiers))
+	for _, v := range verifiers {
+		writers = append(writers, v.hash)
+	}
+
+	if _, err := io.Copy(io.MultiWriter(writers...), io.NewSectionReader(f, 0, stat.Size())); err != nil {
+		return err
+	}
+
+	for _, v := range verifiers {
+		if got := v.encode(v.hash.Sum(nil)); got != v.expect {
+			return fmt.Errorf("%w: %s mismatch: expect %s, got %s", s3common.ErrChecksumMismatch, v.name, v.expect, got)
+		}
+	}
+
+	return nil
+}
+
+func isMD5ETag(etag string, info minio.ObjectInfo) bool {
+	if len(etag) != md5.Size*2 || strings.Contains(etag, "-") {
+		return false
+	}
+
+	// ETag of object encrypted by SSE-KMS or SSE-C is not MD5 of content
+	if info.Metadata.Get("X-Amz-Server-Side-Encryption") == "aws:kms" ||
+		info.Metadata.Get("X-Amz-Server-Side-Encryption-Customer-Algorithm") != "" {
+		return false
+	}
+
+	return true
+}
+
+func isFullObjectChecksum(checksum string, info minio.ObjectInfo) bool {
+	return checksum != "" && !strings.Contains(checksum, "-") && info.ChecksumMode != "COMPOSITE"
+}
@@ -0,0 +1,292 @@
+package s3
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/minio/minio-go/v7/pkg/lifecycle"
+
+	"github.com/ix64/s3-go/s3common"
+)
+
+// lifecycleRuleIDMaxLen S3 限制规则 ID 最长 255 个字符，包括归属标记
+const lifecycleRuleIDMaxLen = 255
+
+// LifecycleRule Config.Prefix 范围内的生命周期规则
+type LifecycleRule struct {
+	// required, unique in Config.Prefix, stored with an ownership marker derived from Config.Prefix
+	ID string
+
+	// optional, relative to Config.Prefix, default to all objects under Config.Prefix
+	Prefix string
+
+	// optional, only apply to objects with all of these tags
+	Tags map[string]string
+
+	// optional, keep the rule but stop applying it
+	Disabled bool
+
+	// optional, expire current version after days since creation
+	ExpirationDays int
+
+	// optional, remove delete markers without noncurrent versions, can not be used with ExpirationDays
+	ExpiredObjectDeleteMarker bool
+
+	// optional, permanently delete noncurrent versions after days since becoming noncurrent
+	NoncurrentExpirationDays int
+
+	// optional, keep the newest noncurrent versions from NoncurrentExpirationDays, which is required
+	NoncurrentNewerVersions int
+
+	// optional, abort incomplete multipart uploads after days since initiation
+	AbortIncompleteMultipartDays int
+
+	// optional, transition current version to another storage class
+	Transition *LifecycleTransition
+
+	// optional, transition noncurrent versions to another storage class
+	NoncurrentTransition *LifecycleTransition
+}
+
+type LifecycleTransition struct {
+	// required, at least 1, days since creation, or since becoming noncurrent for noncurrent versions
+	Days int
+
+	// required, e.g. STANDARD_IA, GLACIER
+	StorageClass string
+}
+
+func (r *LifecycleRule) validate() error {
+	if r.ID == "" {
+		return fmt.Errorf("%w: lifecycle rule id is required", s3common.ErrInvalidArgument)
+	}
+
+	if r.ExpirationDays == 0 && !r.ExpiredObjectDeleteMarker &&
+		r.NoncurrentExpirationDays == 0 && r.AbortIncompleteMultipartDays == 0 &&
+		r.Transition == nil && r.NoncurrentTransition == nil {
+		return fmt.Errorf("%w: lifecycle rule %q has no action", s3common.ErrInvalidArgument, r.ID)
+	}
+
+	// S3 rejects days together with delete marker in the same expiration
+	if r.ExpirationDays > 0 && r.ExpiredObjectDeleteMarker {
+		return fmt.Errorf("%w: lifecycle rule %q can not set both expiration days and expired object delete marker", s3common.ErrInvalidArgument, r.ID)
+	}
+
+	if r.NoncurrentNewerVersions > 0 && r.NoncurrentExpirationDays == 0 {
+		return fmt.Errorf("%w: lifecycle rule %q requires noncurrent expiration days with noncurrent newer versions", s3common.ErrInvalidArgument, r.ID)
+	}
+
+	// zero days is omitted while encoding, which leaves a transition without date
+	for _, t := range []*LifecycleTransition{r.Transition, r.NoncurrentTransition} {
+		if t != nil && (t.Days <= 0 || t.StorageClass == "") {
+			return fmt.Errorf("%w: lifecycle rule %q has invalid transition", s3common.ErrInvalidArgument, r.ID)
+		}
+	}
+
+	return nil
+}
+
+// GetLifecycleRules 获取 Config.Prefix 范围内的生命周期规则，Prefix 为去除 Config.Prefix 后的路径
+func (c *Client) GetLifecycleRules(ctx context.Context) ([]LifecycleRule, error) {
+	config, err := c.getLifecycle(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []LifecycleRule
+	for _, rule := range config.Rules {
+		if c.ownLifecycleRule(rule) {
+			ret = append(ret, c.fromLifecycleRule(rule))
+		}
+	}
+	return ret, nil
+}
+
+// PutLifecycleRules 使用 rules 替换 Config.Prefix 范围内的全部生命周期规则，其他前缀的规则保持不变
+//
+// rules 为空时删除 Config.Prefix 范围内的全部规则
+func (c *Client) PutLifecycleRules(ctx context.Context, rules []LifecycleRule) error {
+	return c.updateLifecycle(ctx, rules, func(owned []lifecycle.Rule, updated []lifecycle.Rule) []lifecycle.Rule {
+		return updated
+	})
+}
+
+// MergeLifecycleRules 按 ID 新增或更新 Config.Prefix 范围内的生命周期规则，未提及的规则保持不变
+func (c *Client) MergeLifecycleRules(ctx context.Context, rules []LifecycleRule) error {
+	return c.updateLifecycle(ctx, rules, func(owned []lifecycle.Rule, updated []lifecycle.Rule) []lifecycle.Rule {
+		for _, rule := range updated {
+			i := slices.IndexFunc(owned, func(r lifecycle.Rule) bool { return r.ID == rule.ID })
+			if i >= 0 {
+				owned[i] = rule
+			} else {
+				owned = append(owned, rule)
+			}
+		}
+		return owned
+	})
+}
+
+// updateLifecycle 读取 bucket 的生命周期配置，使用 merge 更新 Config.Prefix 范围内的规则后写回
+//
+// S3 不支持条件写入生命周期配置，多个服务同时更新时后写入者生效
+func (c *Client) updateLifecycle(ctx context.Context, rules []LifecycleRule, merge func(owned, updated []lifecycle.Rule) []lifecycle.Rule) error {
+	updated := make([]lifecycle.Rule, 0, len(rules))
+	for _, r := range rules {
+		if err := r.validate(); err != nil {
+			return err
+		}
+		if err := c.checkListPrefix(r.Prefix); err != nil {
+			return err
+		}
+		if len(c.lifecycleRuleIDPrefix())+len(r.ID) > lifecycleRuleIDMaxLen {
+			return fmt.Errorf("%w: lifecycle rule id %q is too long", s3common.ErrInvalidArgument, r.ID)
+		}
+		rule := c.toLifecycleRule(r)
+		if slices.ContainsFunc(updated, func(u lifecycle.Rule) bool { return u.ID == rule.ID }) {
+			return fmt.Errorf("%w: duplicate lifecycle rule id %q", s3common.ErrInvalidArgument, r.ID)
+		}
+		updated = append(updated, rule)
+	}
+
+	config, err := c.getLifecycle(ctx)
+	if err != nil {
+		return err
+	}
+
+	var owned, others []lifecycle.Rule
+	for _, rule := range config.Rules {
+		if c.ownLifecycleRule(rule) {
+			owned = append(owned, rule)
+		} else {
+			others = append(others, rule)
+		}
+	}
+
+	config.Rules = append(others, merge(owned, updated)...)
+
+	err = c.c.SetBucketLifecycle(ctx, c.bucket, config)
+	return s3common.ConvertError(err)
+}
+
+func (c *Client) getLifecycle(ctx context.Context) (*lifecycle.Configuration, error) {
+	config, err := c.c.GetBucketLifecycle(ctx, c.bucket)
+	err = s3common.ConvertError(err)
+	if errors.Is(err, s3common.ErrNotFound) {
+		return lifecycle.NewConfiguration(), nil // NoSuchLifecycleConfiguration
+	}
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// lifecycleRuleIDPrefix 规则 ID 的归属标记，由 Config.Prefix 的哈希生成
+//
+// 按规则前缀判断归属时，"app/" 的客户端会覆盖 "app/media/" 客户端的规则，
+// 因此只认领带有自身标记的规则，未带标记的规则（如控制台创建的规则）保持不变
+func (c *Client) lifecycleRuleIDPrefix() string {
+	sum := sha256.Sum256([]byte(c.composeListPrefix("")))
+	return "s3go:" + hex.EncodeToString(sum[:8]) + ":"
+}
+
+// ownLifecycleRule 规则 ID 带有当前客户端的归属标记时，视为属于当前客户端
+func (c *Client) ownLifecycleRule(rule lifecycle.Rule) bool {
+	return strings.HasPrefix(rule.ID, c.lifecycleRuleIDPrefix())
+}
+
+func lifecycleRulePrefix(rule lifecycle.Rule) string {
+	return cmp.Or(rule.RuleFilter.And.Prefix, rule.RuleFilter.Prefix, rule.Prefix)
+}
+
+func (c *Client) toLifecycleRule(r LifecycleRule) lifecycle.Rule {
+	rule := lifecycle.Rule{
+		ID:     c.lifecycleRuleIDPrefix() + r.ID,
+		Status: "Enabled",
+		Expiration: lifecycle.Expiration{
+			Days:         lifecycle.ExpirationDays(r.ExpirationDays),
+			DeleteMarker: lifecycle.ExpireDeleteMarker(r.ExpiredObjectDeleteMarker),
+		},
+		NoncurrentVersionExpiration: lifecycle.NoncurrentVersionExpiration{
+			NoncurrentDays:          lifecycle.ExpirationDays(r.NoncurrentExpirationDays),
+			NewerNoncurrentVersions: r.NoncurrentNewerVersions,
+		},
+		AbortIncompleteMultipartUpload: lifecycle.AbortIncompleteMultipartUpload{
+			DaysAfterInitiation: lifecycle.ExpirationDays(r.AbortIncompleteMultipartDays),
+		},
+	}
+	if r.Disabled {
+		rule.Status = "Disabled"
+	}
+
+	prefix := c.composeListPrefix(r.Prefix)
+	tags := make([]lifecycle.Tag, 0, len(r.Tags))
+	for k, v := range r.Tags {
+		tags = append(tags, lifecycle.Tag{Key: k, Value: v})
+	}
+	slices.SortFunc(tags, func(a, b lifecycle.Tag) int { return strings.Compare(a.Key, b.Key) })
+
+	// And requires at least two predicates
+	switch {
+	case len(tags) == 0:
+		rule.RuleFilter.Prefix = prefix
+	case len(tags) == 1 && prefix == "":
+		rule.RuleFilter.Tag = tags[0]
+	default:
+		rule.RuleFilter.And.Prefix = prefix
+		rule.RuleFilter.And.Tags = tags
+	}
+
+	if t := r.Transition; t != nil {
+		rule.Transition = lifecycle.Transition{
+			Days:         lifecycle.ExpirationDays(t.Days),
+			StorageClass: t.StorageClass,
+		}
+	}
+	if t := r.NoncurrentTransition; t != nil {
+		rule.NoncurrentVersionTransition = lifecycle.NoncurrentVersionTransition{
+			NoncurrentDays: lifecycle.ExpirationDays(t.Days),
+			StorageClass:   t.StorageClass,
+		}
+	}
+
+	return rule
+}
+
+func (c *Client) fromLifecycleRule(rule lifecycle.Rule) LifecycleRule {
+	r := LifecycleRule{
+		ID:                           strings.TrimPrefix(rule.ID, c.lifecycleRuleIDPrefix()),
+		Prefix:                       strings.TrimPrefix(lifecycleRulePrefix(rule), c.composeListPrefix("")),
+		Disabled:                     rule.Status != "Enabled",
+		ExpirationDays:               int(rule.Expiration.Days),
+		ExpiredObjectDeleteMarker:    rule.Expiration.DeleteMarker.IsEnabled(),
+		NoncurrentExpirationDays:     int(rule.NoncurrentVersionExpiration.NoncurrentDays),
+		NoncurrentNewerVersions:      rule.NoncurrentVersionExpiration.NewerNoncurrentVersions,
+		AbortIncompleteMultipartDays: int(rule.AbortIncompleteMultipartUpload.DaysAfterInitiation),
+	}
+
+	tags := rule.RuleFilter.And.Tags
+	if !rule.RuleFilter.Tag.IsEmpty() {
+		tags = append(tags, rule.RuleFilter.Tag)
+	}
+	if len(tags) > 0 {
+		r.Tags = make(map[string]string, len(tags))
+		for _, t := range tags {
+			r.Tags[t.Key] = t.Value
+		}
+	}
+
+	if t := rule.Transition; t.StorageClass != "" {
+		r.Transition = &LifecycleTransition{Days: int(t.Days), StorageClass: t.StorageClass}
+	}
+	if t := rule.NoncurrentVersionTransition; t.StorageClass != "" {
+		r.NoncurrentTransition = &LifecycleTransition{Days: int(t.NoncurrentDays), StorageClass: t.StorageClass}
+	}
+
+	return r
+}
@@ -0,0 +1,171 @@
+package s3_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ix64/s3-go/s3"
+	"github.com/ix64/s3-go/s3common"
+)
+
+func TestClient_PutLifecycleRulesInvalid(t *testing.T) {
+	// no request is sent, rules are validated before reading bucket lifecycle
+	c, err := s3.NewClient(&s3.Config{
+		Endpoint:     "http://127.0.0.1:1",
+		Bucket:       "my-bucket",
+		BucketLookup: s3common.BucketLookupPath,
+		Region:       "us-east-1",
+		AccessKey:    "ak",
+		SecretKey:    "sk",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, rule := range map[string]s3.LifecycleRule{
+		"NoID":     {ExpirationDays: 1},
+		"NoAction": {ID: "rule"},
+		"ExpirationDaysWithDeleteMarker": {
+			ID:                        "rule",
+			ExpirationDays:            30,
+			ExpiredObjectDeleteMarker: true,
+		},
+		"NewerVersionsWithoutDays": {
+			ID:                           "rule",
+			NoncurrentNewerVersions:      3,
+			AbortIncompleteMultipartDays: 7,
+		},
+		"TransitionZeroDays": {
+			ID:         "rule",
+			Transition: &s3.LifecycleTransition{StorageClass: "GLACIER"},
+		},
+		"NoncurrentTransitionNoStorageClass": {
+			ID:                   "rule",
+			NoncurrentTransition: &s3.LifecycleTransition{Days: 30},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := c.PutLifecycleRules(context.Background(), []s3.LifecycleRule{rule})
+			assert.ErrorIs(t, err, s3.ErrInvalidArgument)
+		})
+	}
+}
+
+// lifecycleServer 只实现 bucket 位置查询及生命周期配置的读写
+type lifecycleServer struct {
+	mu     sync.Mutex
+	config []byte
+}
+
+func (s *lifecycleServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if _, ok := r.URL.Query()["location"]; ok {
+		_, _ = io.WriteString(w, `<LocationConstraint>us-east-1</LocationConstraint>`)
+		return
+	}
+	if _, ok := r.URL.Query()["lifecycle"]; !ok {
+		w.WriteHeader(http.StatusNotImplemented)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodGet:
+		if s.config == nil {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, `<Error><Code>NoSuchLifecycleConfiguration</Code></Error>`)
+			return
+		}
+		_, _ = w.Write(s.config)
+	case http.MethodPut:
+		s.config, _ = io.ReadAll(r.Body)
+	case http.MethodDelete:
+		s.config = nil
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestClient_LifecycleOwnership(t *testing.T) {
+	srv := httptest.NewServer(&lifecycleServer{})
+	defer srv.Close()
+
+	newClient := func(prefix string) *s3.Client {
+		c, err := s3.NewClient(&s3.Config{
+			Endpoint:     srv.URL,
+			Bucket:       "my-bucket",
+			BucketLookup: s3common.BucketLookupPath,
+			Region:       "us-east-1",
+			AccessKey:    "ak",
+			SecretKey:    "sk",
+			Prefix:       prefix,
+		})
+		require.NoError(t, err)
+		return c
+	}
+
+	ctx := context.Background()
+	app, media := newClient("app"), newClient("app/media")
+
+	require.NoError(t, media.PutLifecycleRules(ctx, []s3.LifecycleRule{
+		{ID: "expire", Prefix: "tmp/", ExpirationDays: 1},
+	}))
+	require.NoError(t, app.PutLifecycleRules(ctx, []s3.LifecycleRule{
+		{ID: "expire", Tags: map[string]string{"temp": "true"}, ExpirationDays: 7},
+	}))
+
+	// parent prefix does not claim rules of nested prefix
+	rules, err := media.GetLifecycleRules(ctx)
+	require.NoError(t, err)
+	if assert.Len(t, rules, 1) {
+		assert.Equal(t, "expire", rules[0].ID)
+		assert.Equal(t, "tmp/", rules[0].Prefix)
+		assert.Equal(t, 1, rules[0].ExpirationDays)
+	}
+
+	rules, err = app.GetLifecycleRules(ctx)
+	require.NoError(t, err)
+	if assert.Len(t, rules, 1) {
+		assert.Equal(t, "expire", rules[0].ID)
+		assert.Equal(t, map[string]string{"temp": "true"}, rules[0].Tags)
+	}
+
+	require.NoError(t, app.PutLifecycleRules(ctx, nil))
+	rules, err = media.GetLifecycleRules(ctx)
+	require.NoError(t, err)
+	assert.Len(t, rules, 1)
+}
+
+func TestClient_LifecycleTagFilter(t *testing.T) {
+	lc := &lifecycleServer{}
+	srv := httptest.NewServer(lc)
+	defer srv.Close()
+
+	c, err := s3.NewClient(&s3.Config{
+		Endpoint:     srv.URL,
+		Bucket:       "my-bucket",
+		BucketLookup: s3common.BucketLookupPath,
+		Region:       "us-east-1",
+		AccessKey:    "ak",
+		SecretKey:    "sk",
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, c.PutLifecycleRules(context.Background(), []s3.LifecycleRule{
+		{ID: "expire", Tags: map[string]string{"temp": "true"}, ExpirationDays: 7},
+	}))
+
+	// single tag without prefix is a plain Tag filter, And requires two predicates
+	config := string(lc.config)
+	assert.Contains(t, config, "<Filter><Tag><Key>temp</Key><Value>true</Value></Tag></Filter>")
+	assert.NotContains(t, config, "<And>")
+}
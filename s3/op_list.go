@@ -0,0 +1,164 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+
+	"github.com/ix64/s3-go/s3common"
+)
+
+// listDelimiter 非递归列举时使用的 "目录" 分隔符
+const listDelimiter = "/"
+
+type ListOptions struct {
+	// optional, list all objects under prefix instead of grouping by "/"
+	Recursive bool
+
+	// optional, max number of objects and prefixes per page, default to 1000
+	MaxKeys int
+
+	// optional, continue listing from NextContinuationToken of previous page
+	ContinuationToken string
+
+	// optional, start listing after this path (exclusive)
+	StartAfter string
+}
+
+type ListResult struct {
+	// Objects 对象列表，Key 为去除 Config.Prefix 后的路径
+	Objects []minio.ObjectInfo
+
+	// Prefixes 非递归列举时的 "子目录"，以 "/" 结尾
+	Prefixes []string
+
+	// IsTruncated 为 true 时，可使用 NextContinuationToken 继续列举
+	IsTruncated           bool
+	NextContinuationToken string
+}
+
+// List 分页列举指定前缀下的文件
+func (c *Client) List(ctx context.Context, prefix string, opts *ListOptions) (*ListResult, error) {
+	if opts == nil {
+		opts = &ListOptions{}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := c.checkListPrefix(prefix); err != nil {
+		return nil, err
+	}
+
+	delimiter := listDelimiter
+	if opts.Recursive {
+		delimiter = ""
+	}
+
+	startAfter := ""
+	if opts.StartAfter != "" {
+		startAfter = c.composeObjectName(opts.StartAfter)
+	}
+
+	core := minio.Core{Client: c.c}
+	resp, err := core.ListObjectsV2(
+		c.bucket,
+		c.composeListPrefix(prefix),
+		startAfter,
+		opts.ContinuationToken,
+		delimiter,
+		opts.MaxKeys,
+	)
+	if err != nil {
+		return nil, s3common.ConvertError(err)
+	}
+
+	ret := &ListResult{
+		Objects:               make([]minio.ObjectInfo, 0, len(resp.Contents)),
+		Prefixes:              make([]string, 0, len(resp.CommonPrefixes)),
+		IsTruncated:           resp.IsTruncated,
+		NextContinuationToken: resp.NextContinuationToken,
+	}
+
+	for _, obj := range resp.Contents {
+		obj.Key = c.trimObjectName(obj.Key)
+		ret.Objects = append(ret.Objects, obj)
+	}
+
+	for _, p := range resp.CommonPrefixes {
+		ret.Prefixes = append(ret.Prefixes, c.trimObjectName(p.Prefix))
+	}
+
+	return ret, nil
+}
+
+// Walk 递归遍历指定前缀下的所有文件，自动处理分页
+func (c *Client) Walk(ctx context.Context, prefix string) iter.Seq2[minio.ObjectInfo, error] {
+	return func(yield func(minio.ObjectInfo, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel() // stop background listing if caller breaks early
+
+		if err := c.checkListPrefix(prefix); err != nil {
+			yield(minio.ObjectInfo{}, err)
+			return
+		}
+
+		objects := c.c.ListObjectsIter(ctx, c.bucket, minio.ListObjectsOptions{
+			Prefix:    c.composeListPrefix(prefix),
+			Recursive: true,
+		})
+
+		for obj := range objects {
+			if obj.Err != nil {
+				yield(minio.ObjectInfo{}, s3common.ConvertError(obj.Err))
+				return
+			}
+
+			obj.Key = c.trimObjectName(obj.Key)
+			if !yield(obj, nil) {
+				return
+			}
+		}
+	}
+}
+
+// composeListPrefix 将逻辑前缀转换为 s3 列举前缀
+//
+// 空前缀或以 "/" 结尾的前缀视为目录，需要保留结尾的 "/"，
+// 避免 Config.Prefix 为 "app" 时匹配到 "app-other/..."
+func (c *Client) composeListPrefix(prefix string) string {
+	name := c.composeObjectName(prefix)
+	if name != "" && (prefix == "" || strings.HasSuffix(prefix, "/")) {
+		name += "/"
+	}
+	return name
+}
+
+// checkListPrefix 拒绝经 path.Join 清理后跳出 Config.Prefix 的前缀，如 "../other"
+func (c *Client) checkListPrefix(prefix string) error {
+	root := c.composeObjectName("")
+	name := c.composeObjectName(prefix)
+
+	if root == "" {
+		if name != ".." && !strings.HasPrefix(name, "../") {
+			return nil
+		}
+	} else if name == root || strings.HasPrefix(name, root+"/") {
+		return nil
+	}
+
+	return fmt.Errorf("%w: prefix %q is outside of Config.Prefix", s3common.ErrInvalidArgument, prefix)
+}
+
+// trimObjectName 去除 s3 object name 中的 Config.Prefix，还原为逻辑路径
+func (c *Client) trimObjectName(objectName string) string {
+	prefix := c.composeObjectName("")
+	if prefix == "" {
+		return objectName
+	}
+	return strings.TrimPrefix(objectName, prefix+"/")
+}
@@ -0,0 +1,117 @@
+package s3_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ix64/s3-go/s3"
+	"github.com/ix64/s3-go/s3common"
+)
+
+// lockServer 只实现 bucket 位置查询、上传及删除，删除时返回 S3 对锁定对象的拒绝响应
+type lockServer struct {
+	mu        sync.Mutex
+	putHeader http.Header
+}
+
+func (s *lockServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case r.URL.Query().Has("location"):
+		_, _ = fmt.Fprint(w, `<LocationConstraint>us-east-1</LocationConstraint>`)
+	case r.Method == http.MethodPut:
+		s.putHeader = r.Header.Clone()
+		w.Header().Set("ETag", `"etag"`)
+	case r.Method == http.MethodDelete:
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = fmt.Fprint(w, `<Error><Code>AccessDenied</Code><Message>Access Denied because object protected by object lock.</Message></Error>`)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func TestClient_LockInvalid(t *testing.T) {
+	// no request is sent, options are checked before uploading
+	c, err := s3.NewClient(&s3.Config{
+		Endpoint:     "http://127.0.0.1:1",
+		Bucket:       "my-bucket",
+		BucketLookup: s3common.BucketLookupPath,
+		Region:       "us-east-1",
+		AccessKey:    "ak",
+		SecretKey:    "sk",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	until := time.Now().Add(time.Hour)
+
+	for name, opts := range map[string]*s3.PutOptions{
+		"NoRetainUntilDate": {RetentionMode: s3common.RetentionModeGovernance},
+		"UnknownMode":       {RetentionMode: "unknown", RetainUntilDate: until},
+	} {
+		t.Run("Upload"+name, func(t *testing.T) {
+			err := c.UploadWithOptions(ctx, "a.txt", strings.NewReader("a"), 1, opts)
+			assert.ErrorIs(t, err, s3.ErrInvalidArgument)
+		})
+	}
+
+	for name, retention := range map[string]s3.Retention{
+		"NoMode":            {RetainUntilDate: until},
+		"NoRetainUntilDate": {Mode: s3common.RetentionModeCompliance},
+		"UnknownMode":       {Mode: "unknown", RetainUntilDate: until},
+	} {
+		t.Run("SetRetention"+name, func(t *testing.T) {
+			err := c.SetRetention(ctx, "a.txt", retention, nil)
+			assert.ErrorIs(t, err, s3.ErrInvalidArgument)
+		})
+	}
+}
+
+func TestClient_Lock(t *testing.T) {
+	ls := &lockServer{}
+	srv := httptest.NewServer(ls)
+	defer srv.Close()
+
+	c, err := s3.NewClient(&s3.Config{
+		Endpoint:     srv.URL,
+		Bucket:       "my-bucket",
+		BucketLookup: s3common.BucketLookupPath,
+		Region:       "us-east-1",
+		AccessKey:    "ak",
+		SecretKey:    "sk",
+	})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("UploadHeaders", func(t *testing.T) {
+		until := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+		err := c.UploadWithOptions(ctx, "a.txt", strings.NewReader("a"), 1, &s3.PutOptions{
+			RetentionMode:   s3common.RetentionModeCompliance,
+			RetainUntilDate: until,
+			LegalHold:       true,
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "COMPLIANCE", ls.putHeader.Get("X-Amz-Object-Lock-Mode"))
+		assert.Equal(t, "2030-01-02T03:04:05Z", ls.putHeader.Get("X-Amz-Object-Lock-Retain-Until-Date"))
+		assert.Equal(t, "ON", ls.putHeader.Get("X-Amz-Object-Lock-Legal-Hold"))
+		assert.NotEmpty(t, ls.putHeader.Get("Content-Md5"))
+	})
+
+	t.Run("DeleteLocked", func(t *testing.T) {
+		err := c.DeleteWithOptions(ctx, "a.txt", &s3.DeleteOptions{VersionID: "v1"})
+		assert.ErrorIs(t, err, s3.ErrObjectLocked)
+	})
+}
@@ -0,0 +1,466 @@
+package s3
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"mime"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
+
+	"github.com/ix64/s3-go/s3common"
+)
+
+const (
+	multipartMinPartSize     = 5 << 20  // 5 MiB, S3 limit except the last part
+	multipartDefaultPartSize = 16 << 20 // 16 MiB
+	multipartMaxParts        = 10000    // S3 limit
+	multipartDefaultWorkers  = 4
+)
+
+type MultipartOptions struct {
+	// optional, size of each part, default to 16 MiB, at least 5 MiB
+	// automatically increased when the file exceeds 10000 parts
+	PartSize int64
+
+	// optional, number of parts uploaded concurrently, default to 4
+	Concurrency int
+
+	// optional, content-type of uploaded file
+	ContentType string
+
+	// optional, server-side encryption, default to Config.Encryption
+	Encryption encrypt.ServerSide
+
+	// optional, persist checkpoint to resume upload after restart
+	Store MultipartStore
+
+	// optional, key of checkpoint in Store, default to bucket and object name
+	CheckpointKey string
+}
+
+func (o *MultipartOptions) partSize(size int64) int64 {
+	partSize := o.PartSize
+	if partSize <= 0 {
+		partSize = multipartDefaultPartSize
+	}
+	partSize = max(partSize, multipartMinPartSize)
+
+	if minPartSize := (size + multipartMaxParts - 1) / multipartMaxParts; partSize < minPartSize {
+		partSize = minPartSize
+	}
+	return partSize
+}
+
+func (o *MultipartOptions) concurrency() int {
+	if o.Concurrency <= 0 {
+		return multipartDefaultWorkers
+	}
+	return o.Concurrency
+}
+
+// IncompleteUpload 未完成的分片上传
+type IncompleteUpload struct {
+	Path      string
+	UploadID  string
+	Initiated time.Time
+}
+
+// UploadMultipart 分片并发上传，配置 MultipartOptions.Store 后支持断点续传
+func (c *Client) UploadMultipart(ctx context.Context, remotePath string, r io.ReaderAt, size int64, opts *MultipartOptions) error {
+	if opts == nil {
+		opts = &MultipartOptions{}
+	}
+	return c.uploadMultipart(ctx, c.composeObjectName(remotePath), r, size, time.Time{}, opts)
+}
+
+// UploadFileMultipart 将本地文件分片并发上传到远程，本地文件大小或修改时间变化时重新上传
+func (c *Client) UploadFileMultipart(ctx context.Context, remotePath string, localPath string, opts *MultipartOptions) error {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	o := MultipartOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	if o.ContentType == "" {
+		o.ContentType = mime.TypeByExtension(path.Ext(remotePath))
+	}
+
+	return c.uploadMultipart(ctx, c.composeObjectName(remotePath), f, stat.Size(), stat.ModTime(), &o)
+}
+
+// AbortMultipart 取消客户端发起的分片上传，并删除已上传的分片，使用客户端的 bucket 及 prefix
+//
+// opts 可为 nil；设置 MultipartOptions.Store 时同时删除该上传的断点信息
+func (c *Client) AbortMultipart(ctx context.Context, remotePath string, uploadID string, opts *MultipartOptions) error {
+	if opts == nil {
+		opts = &MultipartOptions{}
+	}
+
+	objectName := c.composeObjectName(remotePath)
+	core := minio.Core{Client: c.c}
+	if err := core.AbortMultipartUpload(ctx, c.bucket, objectName, uploadID); err != nil {
+		return s3common.ConvertError(err)
+	}
+
+	return c.deleteCheckpoint(ctx, opts.Store, c.checkpointKey(objectName, opts), uploadID)
+}
+
+// ListIncompleteUploads 列举指定前缀下未完成的分片上传
+func (c *Client) ListIncompleteUploads(ctx context.Context, prefix string) iter.Seq2[IncompleteUpload, error] {
+	return func(yield func(IncompleteUpload, error) bool) {
+		if err := c.checkListPrefix(prefix); err != nil {
+			yield(IncompleteUpload{}, err)
+			return
+		}
+
+		core := minio.Core{Client: c.c}
+		keyMarker, uploadIDMarker := "", ""
+
+		for {
+			resp, err := core.ListMultipartUploads(ctx,
+				c.bucket,
+				c.composeListPrefix(prefix),
+				keyMarker,
+				uploadIDMarker,
+				"",
+				0,
+			)
+			if err != nil {
+				yield(IncompleteUpload{}, s3common.ConvertError(err))
+				return
+			}
+
+			for _, u := range resp.Uploads {
+				if !yield(IncompleteUpload{
+					Path:      c.trimObjectName(u.Key),
+					UploadID:  u.UploadID,
+					Initiated: u.Initiated,
+				}, nil) {
+					return
+				}
+			}
+
+			if !resp.IsTruncated {
+				return
+			}
+			keyMarker, uploadIDMarker = resp.NextKeyMarker, resp.NextUploadIDMarker
+		}
+	}
+}
+
+// CleanupIncompleteUploads 取消指定前缀下超过 olderThan 仍未完成的分片上传，返回取消的数量
+//
+// store 可为 nil；不为 nil 时同时删除使用默认 CheckpointKey 保存的断点信息
+func (c *Client) CleanupIncompleteUploads(ctx context.Context, prefix string, olderThan time.Duration, store MultipartStore) (int, error) {
+	deadline := time.Now().Add(-olderThan)
+
+	var stale []IncompleteUpload
+	for u, err := range c.ListIncompleteUploads(ctx, prefix) {
+		if err != nil {
+			return 0, err
+		}
+		if u.Initiated.Before(deadline) {
+			stale = append(stale, u)
+		}
+	}
+
+	for i, u := range stale {
+		if err := c.AbortMultipart(ctx, u.Path, u.UploadID, &MultipartOptions{Store: store}); err != nil {
+			return i, fmt.Errorf("failed to abort upload %s of %s: %w", u.UploadID, u.Path, err)
+		}
+	}
+
+	return len(stale), nil
+}
+
+func (c *Client) uploadMultipart(ctx context.Context, objectName string, r io.ReaderAt, size int64, modTime time.Time, opts *MultipartOptions) error {
+	core := minio.Core{Client: c.c}
+	partSize := opts.partSize(size)
+
+	key := c.checkpointKey(objectName, opts)
+
+	cp, err := c.resumeMultipart(ctx, core, opts.Store, key, objectName, r, size, modTime, partSize)
+	if err != nil {
+		return err
+	}
+
+	if cp == nil {
+		uploadID, err := core.NewMultipartUpload(ctx, c.bucket, objectName, minio.PutObjectOptions{
+			ContentType:          opts.ContentType,
+			ServerSideEncryption: c.writeSSE(opts.Encryption),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to initiate multipart upload: %w", s3common.ConvertError(err))
+		}
+
+		now := time.Now()
+		cp = &MultipartCheckpoint{
+			UploadID:   uploadID,
+			ObjectName: objectName,
+			Size:       size,
+			ModTime:    modTime,
+			PartSize:   partSize,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+
+		if opts.Store != nil {
+			if err := opts.Store.Save(ctx, key, cp); err != nil {
+				return fmt.Errorf("failed to save checkpoint: %w", err)
+			}
+		}
+	}
+
+	if err := c.uploadMultipartParts(ctx, core, cp, r, opts, key); err != nil {
+		return err
+	}
+
+	slices.SortFunc(cp.Parts, func(a, b MultipartCheckpointPart) int {
+		return a.PartNumber - b.PartNumber
+	})
+
+	completeParts := make([]minio.CompletePart, 0, len(cp.Parts))
+	for _, p := range cp.Parts {
+		completeParts = append(completeParts, minio.CompletePart{PartNumber: p.PartNumber, ETag: p.ETag})
+	}
+
+	if _, err := core.CompleteMultipartUpload(ctx, c.bucket, objectName, cp.UploadID, completeParts, minio.PutObjectOptions{
+		ServerSideEncryption: c.readSSE(opts.Encryption),
+	}); err != nil {
+		return fmt.Errorf("failed to complete multipart upload: %w", s3common.ConvertError(err))
+	}
+
+	if opts.Store != nil {
+		if err := opts.Store.Delete(ctx, key); err != nil {
+			return fmt.Errorf("failed to delete checkpoint: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (c *Client) checkpointKey(objectName string, opts *MultipartOptions) string {
+	if opts.CheckpointKey != "" {
+		return opts.CheckpointKey
+	}
+	return c.cfg.Bucket + "/" + objectName
+}
+
+// deleteCheckpoint 删除 uploadID 对应的断点信息，key 已被其他上传使用时保留
+func (c *Client) deleteCheckpoint(ctx context.Context, store MultipartStore, key string, uploadID string) error {
+	if store == nil {
+		return nil
+	}
+
+	cp, err := store.Load(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to load checkpoint: %w", err)
+	}
+	if cp == nil || cp.UploadID != uploadID {
+		return nil
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		return fmt.Errorf("failed to delete checkpoint: %w", err)
+	}
+	return nil
+}
+
+// resumeMultipart 读取断点信息，并与服务端已上传的分片对齐
+// 无法恢复时返回 nil，由调用方重新发起上传
+//
+// io.ReaderAt 没有修改时间，大小相同时无法判断内容是否变化，
+// 因此重新计算本地分片的 sha256，与断点记录不一致的分片会重新上传
+func (c *Client) resumeMultipart(
+	ctx context.Context,
+	core minio.Core,
+	store MultipartStore,
+	key string,
+	objectName string,
+	r io.ReaderAt,
+	size int64,
+	modTime time.Time,
+	partSize int64,
+) (*MultipartCheckpoint, error) {
+	if store == nil {
+		return nil, nil
+	}
+
+	cp, err := store.Load(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load checkpoint: %w", err)
+	}
+	if cp == nil {
+		return nil, nil
+	}
+
+	if cp.ObjectName != objectName || cp.Size != size || !cp.ModTime.Equal(modTime) || cp.PartSize != partSize {
+		// source changed, previous parts are useless
+		_ = core.AbortMultipartUpload(ctx, c.bucket, cp.ObjectName, cp.UploadID)
+		return nil, store.Delete(ctx, key)
+	}
+
+	uploaded := make(map[int]string)
+	partNumberMarker := 0
+	for {
+		resp, err := core.ListObjectParts(ctx, c.bucket, objectName, cp.UploadID, partNumberMarker, 0)
+		err = s3common.ConvertError(err)
+		if errors.Is(err, s3common.ErrNotFound) {
+			// aborted or expired by lifecycle rule
+			return nil, store.Delete(ctx, key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list uploaded parts: %w", err)
+		}
+
+		for _, p := range resp.ObjectParts {
+			uploaded[p.PartNumber] = trimETag(p.ETag)
+		}
+
+		if !resp.IsTruncated {
+			break
+		}
+		partNumberMarker = resp.NextPartNumberMarker
+	}
+
+	// keep parts confirmed by server and unchanged locally only
+	parts := cp.Parts[:0]
+	for _, p := range cp.Parts {
+		if uploaded[p.PartNumber] != trimETag(p.ETag) {
+			continue
+		}
+
+		offset := int64(p.PartNumber-1) * partSize
+		if offset > size || min(partSize, size-offset) != p.Size {
+			continue
+		}
+		sum, err := sha256Hex(io.NewSectionReader(r, offset, p.Size))
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash part %d: %w", p.PartNumber, err)
+		}
+		if sum != p.Sha256 {
+			continue
+		}
+
+		parts = append(parts, p)
+	}
+	cp.Parts = parts
+
+	return cp, nil
+}
+
+func sha256Hex(r io.Reader) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (c *Client) uploadMultipartParts(
+	ctx context.Context,
+	core minio.Core,
+	cp *MultipartCheckpoint,
+	r io.ReaderAt,
+	opts *MultipartOptions,
+	key string,
+) error {
+	done := make(map[int]bool, len(cp.Parts))
+	for _, p := range cp.Parts {
+		done[p.PartNumber] = true
+	}
+
+	partCount := int((cp.Size + cp.PartSize - 1) / cp.PartSize)
+	if partCount == 0 {
+		partCount = 1 // empty file is uploaded as a single empty part
+	}
+
+	pending := make([]int, 0, partCount)
+	for partNumber := 1; partNumber <= partCount; partNumber++ {
+		if !done[partNumber] {
+			pending = append(pending, partNumber)
+		}
+	}
+
+	var mu sync.Mutex
+	return runParallel(ctx, pending, opts.concurrency(), func(ctx context.Context, partNumber int) error {
+		offset := int64(partNumber-1) * cp.PartSize
+		section := io.NewSectionReader(r, offset, min(cp.PartSize, cp.Size-offset))
+		part, err := c.uploadMultipartPart(ctx, core, cp, section, partNumber, c.readSSE(opts.Encryption))
+		if err != nil {
+			return fmt.Errorf("failed to upload part %d: %w", partNumber, err)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		cp.Parts = append(cp.Parts, part)
+		cp.UpdatedAt = time.Now()
+		if opts.Store != nil {
+			if err := opts.Store.Save(ctx, key, cp); err != nil {
+				return fmt.Errorf("failed to save checkpoint: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// uploadMultipartPart 上传单个分片，SSE-C 时 sse 为上传使用的密钥
+func (c *Client) uploadMultipartPart(ctx context.Context, core minio.Core, cp *MultipartCheckpoint, section *io.SectionReader, partNumber int, sse encrypt.ServerSide) (MultipartCheckpointPart, error) {
+	md5Hash, sha256Hash := md5.New(), sha256.New()
+	if _, err := io.Copy(io.MultiWriter(md5Hash, sha256Hash), section); err != nil {
+		return MultipartCheckpointPart{}, err
+	}
+	if _, err := section.Seek(0, io.SeekStart); err != nil {
+		return MultipartCheckpointPart{}, err
+	}
+
+	sha256Hex := hex.EncodeToString(sha256Hash.Sum(nil))
+
+	part, err := core.PutObjectPart(ctx, c.bucket, cp.ObjectName, cp.UploadID, partNumber, section, section.Size(), minio.PutObjectPartOptions{
+		Md5Base64: base64.StdEncoding.EncodeToString(md5Hash.Sum(nil)),
+		Sha256Hex: sha256Hex,
+		SSE:       sse,
+	})
+	if err != nil {
+		return MultipartCheckpointPart{}, s3common.ConvertError(err)
+	}
+
+	if part.ETag == "" {
+		return MultipartCheckpointPart{}, errors.New("empty etag returned")
+	}
+
+	return MultipartCheckpointPart{
+		PartNumber: partNumber,
+		Size:       section.Size(),
+		ETag:       trimETag(part.ETag),
+		Sha256:     sha256Hex,
+	}, nil
+}
+
+func trimETag(etag string) string {
+	return strings.Trim(etag, "\"")
+}
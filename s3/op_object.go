@@ -1,78 +1,379 @@
 package s3
 
 import (
+	"cmp"
 	"context"
+	"fmt"
 	"io"
 	"mime"
 	"net/url"
 	"path"
+	"time"
 
 	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
 
+	"github.com/ix64/s3-go/s3common"
 	"github.com/ix64/s3-go/s3down"
 	"github.com/ix64/s3-go/s3up"
 )
 
+type PutOptions struct {
+	// optional, content-type of uploaded file
+	ContentType string
+
+	// optional, user metadata, stored as "x-amz-meta-*" headers
+	UserMetadata map[string]string
+
+	// optional, object tags
+	Tags map[string]string
+
+	// optional, standard headers returned while downloading
+	CacheControl       string
+	ContentDisposition string
+	ContentEncoding    string
+	ContentLanguage    string
+	Expires            time.Time
+
+	// optional, storage class, e.g. STANDARD, STANDARD_IA, GLACIER, default to STANDARD
+	StorageClass string
+
+	// optional, apply Object Lock retention, RetainUntilDate is required with RetentionMode
+	RetentionMode   s3common.RetentionMode
+	RetainUntilDate time.Time
+
+	// optional, apply Object Lock legal hold
+	LegalHold bool
+
+	// optional, server-side encryption, default to Config.Encryption
+	Encryption encrypt.ServerSide
+}
+
+func (o *PutOptions) toPutObjectOptions(c *Client) minio.PutObjectOptions {
+	ret := minio.PutObjectOptions{
+		ContentType:          o.ContentType,
+		UserMetadata:         o.UserMetadata,
+		UserTags:             o.Tags,
+		CacheControl:         o.CacheControl,
+		ContentDisposition:   o.ContentDisposition,
+		ContentEncoding:      o.ContentEncoding,
+		ContentLanguage:      o.ContentLanguage,
+		Expires:              o.Expires,
+		StorageClass:         o.StorageClass,
+		ServerSideEncryption: c.writeSSE(o.Encryption),
+	}
+
+	if o.RetentionMode != "" {
+		ret.Mode = minio.RetentionMode(o.RetentionMode)
+		ret.RetainUntilDate = o.RetainUntilDate
+	}
+	if o.LegalHold {
+		ret.LegalHold = minio.LegalHoldEnabled
+	}
+	if o.RetentionMode != "" || o.LegalHold {
+		// Content-MD5 or checksum is required by Object Lock
+		ret.SendContentMd5 = true
+	}
+
+	return ret
+}
+
 // Upload 将 io.Reader 的内容上传到远程的文件
 func (c *Client) Upload(ctx context.Context, remotePath string, file io.Reader, size int64, mime string) error {
-	_, err := c.c.PutObject(ctx, c.cfg.Bucket, c.composeObjectName(remotePath), file, size, minio.PutObjectOptions{ContentType: mime})
-	return err
+	return c.UploadWithOptions(ctx, remotePath, file, size, &PutOptions{ContentType: mime})
+}
+
+// UploadWithOptions 将 io.Reader 的内容按指定选项上传到远程的文件
+func (c *Client) UploadWithOptions(ctx context.Context, remotePath string, file io.Reader, size int64, opts *PutOptions) error {
+	if opts == nil {
+		opts = &PutOptions{}
+	}
+
+	if opts.RetentionMode != "" && (!opts.RetentionMode.IsValid() || opts.RetainUntilDate.IsZero()) {
+		return fmt.Errorf("%w: valid retention mode and retain until date are required together", s3common.ErrInvalidArgument)
+	}
+
+	_, err := c.c.PutObject(ctx, c.bucket, c.composeObjectName(remotePath), file, size, opts.toPutObjectOptions(c))
+	return s3common.ConvertError(err)
 }
 
 // UploadFile 将本地文件上传到远程
 func (c *Client) UploadFile(ctx context.Context, remotePath string, localPath string) error {
 	_, err := c.c.FPutObject(ctx,
-		c.cfg.Bucket,
+		c.bucket,
 		c.composeObjectName(remotePath),
 		localPath,
 		minio.PutObjectOptions{
-			ContentType: mime.TypeByExtension(path.Ext(remotePath)),
+			ContentType:          mime.TypeByExtension(path.Ext(remotePath)),
+			ServerSideEncryption: c.sse,
 		},
 	)
-	return err
+	return s3common.ConvertError(err)
 }
 
 // Download 获取文件内容，返回 io.ReadCloser
+//
+// 请求在首次读取时才会发出，文件不存在等错误由 Read 返回
 func (c *Client) Download(ctx context.Context, remotePath string) (io.ReadCloser, error) {
-	return c.c.GetObject(ctx,
-		c.cfg.Bucket,
+	obj, err := c.c.GetObject(ctx,
+		c.bucket,
 		c.composeObjectName(remotePath),
-		minio.GetObjectOptions{},
+		minio.GetObjectOptions{ServerSideEncryption: c.readSSE(nil)},
 	)
+	if err != nil {
+		return nil, s3common.ConvertError(err)
+	}
+	return objectReader{obj}, nil
+}
+
+// objectReader 转换 minio.Object 读取时返回的错误
+type objectReader struct {
+	*minio.Object
+}
+
+func (r objectReader) Read(p []byte) (int, error) {
+	n, err := r.Object.Read(p)
+	if err != nil && err != io.EOF {
+		err = s3common.ConvertError(err)
+	}
+	return n, err
 }
 
-// DownloadFile 下载文件到指定的本地路径
+// DownloadFile 下载文件到指定的本地路径，大文件分段并发下载，参见 DownloadFileWithOptions
 func (c *Client) DownloadFile(ctx context.Context, remotePath string, localPath string) error {
-	return c.c.FGetObject(ctx,
-		c.cfg.Bucket,
-		c.composeObjectName(remotePath),
-		localPath,
-		minio.GetObjectOptions{},
-	)
+	return c.DownloadFileWithOptions(ctx, remotePath, localPath, nil)
+}
+
+type StatOptions struct {
+	// optional, stat a specific version instead of the latest one
+	VersionID string
+
+	// optional, SSE-C key of object, default to Config.Encryption
+	Encryption encrypt.ServerSide
 }
 
 // Stat 获取文件信息
 func (c *Client) Stat(ctx context.Context, remotePath string) (minio.ObjectInfo, error) {
-	return c.c.StatObject(ctx, c.cfg.Bucket, c.composeObjectName(remotePath), minio.StatObjectOptions{
-		Checksum: true,
+	return c.StatWithOptions(ctx, remotePath, nil)
+}
+
+// StatWithOptions 按指定选项获取文件信息
+func (c *Client) StatWithOptions(ctx context.Context, remotePath string, opts *StatOptions) (minio.ObjectInfo, error) {
+	if opts == nil {
+		opts = &StatOptions{}
+	}
+
+	info, err := c.c.StatObject(ctx, c.bucket, c.composeObjectName(remotePath), minio.StatObjectOptions{
+		ServerSideEncryption: c.readSSE(opts.Encryption),
+		VersionID:            opts.VersionID,
+		Checksum:             true,
 	})
+	return info, s3common.ConvertError(err)
+}
+
+type DeleteOptions struct {
+	// optional, permanently delete a specific version,
+	// otherwise a delete marker is created when versioning is enabled
+	VersionID string
+
+	// optional, delete version under GOVERNANCE retention,
+	// requires s3:BypassGovernanceRetention permission
+	BypassGovernance bool
 }
 
 // Delete 删除文件
 func (c *Client) Delete(ctx context.Context, remotePath string) error {
-	return c.c.RemoveObject(ctx, c.cfg.Bucket, c.composeObjectName(remotePath), minio.RemoveObjectOptions{})
+	return c.DeleteWithOptions(ctx, remotePath, nil)
+}
+
+// DeleteWithOptions 按指定选项删除文件
+func (c *Client) DeleteWithOptions(ctx context.Context, remotePath string, opts *DeleteOptions) error {
+	if opts == nil {
+		opts = &DeleteOptions{}
+	}
+
+	err := c.c.RemoveObject(ctx, c.bucket, c.composeObjectName(remotePath), minio.RemoveObjectOptions{
+		VersionID:        opts.VersionID,
+		GovernanceBypass: opts.BypassGovernance,
+	})
+	return s3common.ConvertError(err)
+}
+
+type CopyOptions struct {
+	// optional, copy a specific version of source object instead of the latest one
+	SourceVersionID string
+
+	// optional, SSE-C key of source object, default to Config.Encryption
+	SourceEncryption encrypt.ServerSide
+
+	// optional, server-side encryption of destination object, default to Config.Encryption
+	Encryption encrypt.ServerSide
+
+	// optional, objects larger than it are copied by parts concurrently, default to and at most 5 GiB,
+	// which is the limit of single CopyObject
+	MultipartThreshold int64
+
+	// optional, size of each part copied, default to 512 MiB, at least 5 MiB and at most 5 GiB,
+	// automatically increased when the object exceeds 10000 parts
+	PartSize int64
+
+	// optional, number of parts copied concurrently, default to 4
+	Concurrency int
+
+	// optional, multipart copy requests a full object checksum of the same algorithm as source and compares them,
+	// source without full object CRC64NVME, CRC32C or CRC32 checksum returns ErrNotSupported unless allowed to verify size only
+	AllowSizeOnlyVerification bool
+
+	// optional, called with bytes copied and total size after each part or chunk,
+	// calls are serialized, should return quickly
+	Progress func(copied, total int64)
 }
 
 // Copy 远程复制文件
 func (c *Client) Copy(ctx context.Context, oldPath string, newPath string) error {
-	srcOpts := minio.CopySrcOptions{Bucket: c.cfg.Bucket, Object: c.composeObjectName(oldPath)}
-	dstOpts := minio.CopyDestOptions{Bucket: c.cfg.Bucket, Object: c.composeObjectName(newPath)}
+	return c.CopyWithOptions(ctx, oldPath, newPath, nil)
+}
+
+// CopyWithOptions 按指定选项远程复制文件，可用于更换加密方式或 SSE-C 密钥
+//
+// 超过 MultipartThreshold 的对象使用 UploadPartCopy 分片并发复制，保留元数据及标签，并校验复制结果的完整对象校验和
+func (c *Client) CopyWithOptions(ctx context.Context, oldPath string, newPath string, opts *CopyOptions) error {
+	if opts == nil {
+		opts = &CopyOptions{}
+	}
+
+	info, err := c.StatWithOptions(ctx, oldPath, &StatOptions{
+		VersionID:  opts.SourceVersionID,
+		Encryption: opts.SourceEncryption,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.copyObject(ctx, c, info, c.composeObjectName(oldPath), c.composeObjectName(newPath), opts)
+}
+
+// copyObject 服务端复制 src 客户端的对象到 c，两者需为同一服务
+func (c *Client) copyObject(ctx context.Context, src *Client, info minio.ObjectInfo, srcObject, dstObject string, opts *CopyOptions) error {
+	if info.Size > opts.multipartThreshold() {
+		putOpts, err := c.copyPutOptions(ctx, src, info, srcObject, c.writeSSE(opts.Encryption))
+		if err != nil {
+			return err
+		}
+		return c.copyMultipart(ctx, src, info, srcObject, dstObject, putOpts, opts)
+	}
+
+	// copy source is resolved by server, always use the real bucket
+	srcOpts := minio.CopySrcOptions{
+		Bucket:     src.cfg.Bucket,
+		Object:     srcObject,
+		VersionID:  opts.SourceVersionID,
+		MatchETag:  info.ETag, // make sure object is not changed since stat
+		Encryption: encrypt.SSECopy(src.readSSE(opts.SourceEncryption)),
+	}
+	dstOpts := minio.CopyDestOptions{
+		Bucket:     c.bucket,
+		Object:     dstObject,
+		Encryption: c.writeSSE(opts.Encryption),
+	}
+
+	if _, err := c.c.CopyObject(ctx, dstOpts, srcOpts); err != nil {
+		return s3common.ConvertError(err)
+	}
+
+	if opts.Progress != nil {
+		opts.Progress(info.Size, info.Size)
+	}
+	return nil
+}
+
+// UpdateMetadata 原地复制对象以替换元数据，对象内容、标签及加密方式不变
+//
+// UserMetadata 被整体替换；ContentType 等标准头及 StorageClass 未设置时保留原值；
+// opts.Encryption 未设置时沿用对象当前的加密方式，超过 5 GiB 的对象自动分片复制。
+// 原地复制无法修改标签及对象锁定，设置 Tags、RetentionMode、RetainUntilDate 或 LegalHold 时返回 ErrInvalidArgument，
+// 需要时使用 SetTags、SetRetention 及 SetLegalHold
+func (c *Client) UpdateMetadata(ctx context.Context, remotePath string, opts *PutOptions) error {
+	if opts == nil {
+		opts = &PutOptions{}
+	}
+
+	if len(opts.Tags) > 0 {
+		return fmt.Errorf("%w: tags can not be updated with metadata, use SetTags instead", s3common.ErrInvalidArgument)
+	}
+	if opts.RetentionMode != "" || !opts.RetainUntilDate.IsZero() || opts.LegalHold {
+		return fmt.Errorf("%w: object lock can not be updated with metadata, use SetRetention or SetLegalHold instead", s3common.ErrInvalidArgument)
+	}
+
+	info, err := c.StatWithOptions(ctx, remotePath, &StatOptions{Encryption: opts.Encryption})
+	if err != nil {
+		return err
+	}
+
+	sse := opts.Encryption
+	if sse == nil {
+		if sse, err = objectSSE(info, c.readSSE(nil)); err != nil {
+			return err
+		}
+	}
+
+	objectName := c.composeObjectName(remotePath)
+	putOpts, err := c.copyPutOptions(ctx, c, info, objectName, sse)
+	if err != nil {
+		return err
+	}
+
+	putOpts.UserMetadata = opts.UserMetadata
+	putOpts.ContentType = cmp.Or(opts.ContentType, putOpts.ContentType)
+	putOpts.CacheControl = cmp.Or(opts.CacheControl, putOpts.CacheControl)
+	putOpts.ContentDisposition = cmp.Or(opts.ContentDisposition, putOpts.ContentDisposition)
+	putOpts.ContentEncoding = cmp.Or(opts.ContentEncoding, putOpts.ContentEncoding)
+	putOpts.ContentLanguage = cmp.Or(opts.ContentLanguage, putOpts.ContentLanguage)
+	putOpts.StorageClass = cmp.Or(opts.StorageClass, putOpts.StorageClass)
+	if !opts.Expires.IsZero() {
+		putOpts.Expires = opts.Expires
+	}
+
+	if info.Size > copyMaxSize {
+		return c.copyMultipart(ctx, c, info, objectName, objectName, putOpts, &CopyOptions{
+			SourceEncryption: opts.Encryption,
+			Encryption:       sse,
+		})
+	}
+
+	metadata := make(map[string]string, len(putOpts.UserMetadata)+1)
+	for k, v := range putOpts.UserMetadata {
+		metadata[k] = v
+	}
+	if putOpts.StorageClass != "" {
+		metadata["X-Amz-Storage-Class"] = putOpts.StorageClass
+	}
+
+	srcOpts := minio.CopySrcOptions{
+		Bucket:     c.cfg.Bucket,
+		Object:     objectName,
+		MatchETag:  info.ETag, // make sure object is not changed since stat
+		Encryption: encrypt.SSECopy(c.readSSE(opts.Encryption)),
+	}
+	dstOpts := minio.CopyDestOptions{
+		Bucket:             c.bucket,
+		Object:             objectName,
+		ReplaceMetadata:    true,
+		UserMetadata:       metadata,
+		ContentType:        putOpts.ContentType,
+		CacheControl:       putOpts.CacheControl,
+		ContentDisposition: putOpts.ContentDisposition,
+		ContentEncoding:    putOpts.ContentEncoding,
+		ContentLanguage:    putOpts.ContentLanguage,
+		Expires:            putOpts.Expires,
+		Encryption:         sse,
+	}
 
-	_, err := c.c.CopyObject(ctx, dstOpts, srcOpts)
-	return err
+	_, err = c.c.CopyObject(ctx, dstOpts, srcOpts)
+	return s3common.ConvertError(err)
 }
 
-// Move 远程移动文件（复制后删除）
+// Move 远程移动文件（复制后删除），超过 5 GiB 的对象自动分片复制
 func (c *Client) Move(ctx context.Context, oldPath, newPath string) error {
 	if err := c.Copy(ctx, oldPath, newPath); err != nil {
 		return err
@@ -89,3 +390,39 @@ func (c *Client) GenerateDownload(ctx context.Context, params *s3down.GeneratePa
 func (c *Client) GenerateUpload(ctx context.Context, param *s3up.GenerateParams) (*s3up.GenerateResult, error) {
 	return c.upload.GenerateUpload(ctx, param)
 }
+
+// GenerateMultipartUpload 前端直连分片上传 发起上传并预签名生成各分片的上传链接
+func (c *Client) GenerateMultipartUpload(ctx context.Context, params *s3up.MultipartGenerateParams) (*s3up.MultipartGenerateResult, error) {
+	g, err := c.multipartUploadGenerator()
+	if err != nil {
+		return nil, err
+	}
+	return g.GenerateMultipartUpload(ctx, params)
+}
+
+// CompleteMultipartUpload 前端直连分片上传 校验客户端上报的分片后完成上传
+func (c *Client) CompleteMultipartUpload(ctx context.Context, params *s3up.MultipartCompleteParams) error {
+	g, err := c.multipartUploadGenerator()
+	if err != nil {
+		return err
+	}
+	return g.CompleteMultipartUpload(ctx, params)
+}
+
+// AbortMultipartUpload 前端直连分片上传 取消 GenerateMultipartUpload 发起的上传，
+// 使用上传生成器的 bucket 及 prefix；UploadMultipart 等客户端发起的分片上传请使用 AbortMultipart
+func (c *Client) AbortMultipartUpload(ctx context.Context, remotePath string, uploadID string) error {
+	g, err := c.multipartUploadGenerator()
+	if err != nil {
+		return err
+	}
+	return g.AbortMultipartUpload(ctx, remotePath, uploadID)
+}
+
+func (c *Client) multipartUploadGenerator() (s3up.MultipartGenerator, error) {
+	g, ok := c.upload.(s3up.MultipartGenerator)
+	if !ok {
+		return nil, fmt.Errorf("%w: upload generator does not support multipart upload", s3common.ErrNotSupported)
+	}
+	return g, nil
+}
@@ -0,0 +1,74 @@
+package s3_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ix64/s3-go/s3"
+	"github.com/ix64/s3-go/s3common"
+)
+
+func TestClient_UpdateMetadataInvalid(t *testing.T) {
+	// no request is sent, options are checked before stat
+	c, err := s3.NewClient(&s3.Config{
+		Endpoint:     "http://127.0.0.1:1",
+		Bucket:       "my-bucket",
+		BucketLookup: s3common.BucketLookupPath,
+		Region:       "us-east-1",
+		AccessKey:    "ak",
+		SecretKey:    "sk",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, opts := range map[string]*s3.PutOptions{
+		"Tags":            {Tags: map[string]string{"k": "v"}},
+		"RetentionMode":   {RetentionMode: s3common.RetentionModeGovernance},
+		"RetainUntilDate": {RetainUntilDate: time.Now().Add(time.Hour)},
+		"LegalHold":       {LegalHold: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := c.UpdateMetadata(context.Background(), "a.txt", opts)
+			assert.ErrorIs(t, err, s3.ErrInvalidArgument)
+		})
+	}
+}
+
+func TestObjectSSE(t *testing.T) {
+	ssec := encrypt.DefaultPBKDF([]byte("password"), []byte("salt"))
+
+	for name, tc := range map[string]struct {
+		header http.Header
+		typ    encrypt.Type
+	}{
+		"None":   {http.Header{}, ""},
+		"SSE-S3": {http.Header{"X-Amz-Server-Side-Encryption": {"AES256"}}, encrypt.S3},
+		"SSE-KMS": {http.Header{
+			"X-Amz-Server-Side-Encryption":                {"aws:kms"},
+			"X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id": {"my-key"},
+		}, encrypt.KMS},
+		"SSE-C": {http.Header{"X-Amz-Server-Side-Encryption-Customer-Algorithm": {"AES256"}}, encrypt.SSEC},
+	} {
+		t.Run(name, func(t *testing.T) {
+			sse, err := s3.ObjectSSE(minio.ObjectInfo{Metadata: tc.header}, ssec)
+			assert.NoError(t, err)
+			if tc.typ == "" {
+				assert.Nil(t, sse)
+				return
+			}
+			if assert.NotNil(t, sse) {
+				assert.Equal(t, tc.typ, sse.Type())
+			}
+		})
+	}
+
+	_, err := s3.ObjectSSE(minio.ObjectInfo{Metadata: http.Header{"X-Amz-Server-Side-Encryption": {"unknown"}}}, nil)
+	assert.ErrorIs(t, err, s3.ErrInvalidArgument)
+}
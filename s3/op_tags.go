@@ -0,0 +1,37 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/tags"
+
+	"github.com/ix64/s3-go/s3common"
+)
+
+// GetTags 获取对象标签
+func (c *Client) GetTags(ctx context.Context, remotePath string) (map[string]string, error) {
+	t, err := c.c.GetObjectTagging(ctx, c.bucket, c.composeObjectName(remotePath), minio.GetObjectTaggingOptions{})
+	if err != nil {
+		return nil, s3common.ConvertError(err)
+	}
+	return t.ToMap(), nil
+}
+
+// SetTags 替换对象的全部标签
+func (c *Client) SetTags(ctx context.Context, remotePath string, objectTags map[string]string) error {
+	t, err := tags.NewTags(objectTags, true)
+	if err != nil {
+		return fmt.Errorf("%w: %w", s3common.ErrInvalidArgument, err)
+	}
+
+	err = c.c.PutObjectTagging(ctx, c.bucket, c.composeObjectName(remotePath), t, minio.PutObjectTaggingOptions{})
+	return s3common.ConvertError(err)
+}
+
+// DeleteTags 删除对象的全部标签
+func (c *Client) DeleteTags(ctx context.Context, remotePath string) error {
+	err := c.c.RemoveObjectTagging(ctx, c.bucket, c.composeObjectName(remotePath), minio.RemoveObjectTaggingOptions{})
+	return s3common.ConvertError(err)
+}
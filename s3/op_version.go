@@ -0,0 +1,54 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"iter"
+
+	"github.com/minio/minio-go/v7"
+
+	"github.com/ix64/s3-go/s3common"
+)
+
+// ListVersions 递归遍历指定前缀下所有对象的全部版本，包括删除标记
+//
+// 同一对象的版本按从新到旧的顺序返回，IsLatest 标记当前版本，IsDeleteMarker 标记删除标记
+func (c *Client) ListVersions(ctx context.Context, prefix string) iter.Seq2[minio.ObjectInfo, error] {
+	return func(yield func(minio.ObjectInfo, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel() // stop background listing if caller breaks early
+
+		if err := c.checkListPrefix(prefix); err != nil {
+			yield(minio.ObjectInfo{}, err)
+			return
+		}
+
+		objects := c.c.ListObjectsIter(ctx, c.bucket, minio.ListObjectsOptions{
+			Prefix:       c.composeListPrefix(prefix),
+			Recursive:    true,
+			WithVersions: true,
+		})
+
+		for obj := range objects {
+			if obj.Err != nil {
+				yield(minio.ObjectInfo{}, s3common.ConvertError(obj.Err))
+				return
+			}
+
+			obj.Key = c.trimObjectName(obj.Key)
+			if !yield(obj, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Restore 将指定的历史版本复制为当前版本，历史版本本身保留
+//
+// versionID 不能是删除标记；若当前版本为删除标记，恢复后删除标记成为历史版本
+func (c *Client) Restore(ctx context.Context, remotePath string, versionID string) error {
+	if versionID == "" {
+		return fmt.Errorf("%w: version id is required", s3common.ErrInvalidArgument)
+	}
+	return c.CopyWithOptions(ctx, remotePath, remotePath, &CopyOptions{SourceVersionID: versionID})
+}
@@ -0,0 +1,29 @@
+package s3_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ix64/s3-go/s3"
+	"github.com/ix64/s3-go/s3common"
+)
+
+func TestClient_RestoreWithoutVersion(t *testing.T) {
+	// no request is sent, version id is checked before copying
+	c, err := s3.NewClient(&s3.Config{
+		Endpoint:     "http://127.0.0.1:1",
+		Bucket:       "my-bucket",
+		BucketLookup: s3common.BucketLookupPath,
+		Region:       "us-east-1",
+		AccessKey:    "ak",
+		SecretKey:    "sk",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = c.Restore(context.Background(), "a.txt", "")
+	assert.ErrorIs(t, err, s3.ErrInvalidArgument)
+}
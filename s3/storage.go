@@ -0,0 +1,43 @@
+package s3
+
+import (
+	"context"
+	"io"
+	"iter"
+	"net/url"
+
+	"github.com/minio/minio-go/v7"
+
+	"github.com/ix64/s3-go/s3down"
+	"github.com/ix64/s3-go/s3up"
+)
+
+// Storage 对象存储的常用操作，由 *Client 实现
+//
+// 业务代码依赖 Storage 而非 *Client 时，单元测试可使用 s3mem 包的内存实现，无需启动 MinIO
+type Storage interface {
+	Upload(ctx context.Context, remotePath string, file io.Reader, size int64, mime string) error
+	UploadFile(ctx context.Context, remotePath string, localPath string) error
+
+	Download(ctx context.Context, remotePath string) (io.ReadCloser, error)
+	DownloadWithOptions(ctx context.Context, remotePath string, opts *DownloadOptions) (*DownloadResult, error)
+	DownloadFile(ctx context.Context, remotePath string, localPath string) error
+
+	Stat(ctx context.Context, remotePath string) (minio.ObjectInfo, error)
+	Delete(ctx context.Context, remotePath string) error
+	Copy(ctx context.Context, oldPath string, newPath string) error
+	Move(ctx context.Context, oldPath, newPath string) error
+
+	List(ctx context.Context, prefix string, opts *ListOptions) (*ListResult, error)
+	Walk(ctx context.Context, prefix string) iter.Seq2[minio.ObjectInfo, error]
+	DeleteMany(ctx context.Context, paths []string) ([]*DeleteError, error)
+
+	// DeletePrefix 删除前缀下的所有文件，prefix 与 Config.Prefix 均为空时返回 ErrInvalidArgument
+	// prefix 按字符串前缀匹配而非目录，"user/12" 同时删除 "user/123/" 下的文件，只删除目录时需以 "/" 结尾
+	DeletePrefix(ctx context.Context, prefix string) ([]*DeleteError, error)
+
+	GenerateDownload(ctx context.Context, params *s3down.GenerateParams) (*url.URL, error)
+	GenerateUpload(ctx context.Context, param *s3up.GenerateParams) (*s3up.GenerateResult, error)
+}
+
+var _ Storage = (*Client)(nil)
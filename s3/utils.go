@@ -0,0 +1,40 @@
+package s3
+
+import (
+	"context"
+	"sync"
+)
+
+// runParallel 使用最多 concurrency 个 goroutine 执行 tasks，任一任务失败时取消其余任务并返回该错误
+func runParallel[T any](ctx context.Context, tasks []T, concurrency int, fn func(ctx context.Context, task T) error) error {
+	pending := make(chan T, len(tasks))
+	for _, task := range tasks {
+		pending <- task
+	}
+	close(pending)
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	var wg sync.WaitGroup
+	for range min(concurrency, len(tasks)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for task := range pending {
+				if ctx.Err() != nil {
+					return
+				}
+				if err := fn(ctx, task); err != nil {
+					cancel(err)
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return context.Cause(ctx)
+}
@@ -0,0 +1,271 @@
+package s3common
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+type CredentialsType string
+
+const (
+	// CredentialsTypeStatic 固定的 AccessKey、SecretKey 及可选的 SessionToken
+	CredentialsTypeStatic CredentialsType = "static"
+
+	// CredentialsTypeEnv 读取环境变量
+	// AWS_ACCESS_KEY_ID、AWS_SECRET_ACCESS_KEY、AWS_SESSION_TOKEN 或 MINIO_ROOT_USER、MINIO_ROOT_PASSWORD
+	CredentialsTypeEnv CredentialsType = "env"
+
+	// CredentialsTypeFile 读取共享凭证文件，默认为 $AWS_SHARED_CREDENTIALS_FILE 或 ~/.aws/credentials，
+	// 支持 credential_process
+	CredentialsTypeFile CredentialsType = "file"
+
+	// CredentialsTypeAssumeRole 使用 AccessKey、SecretKey 调用 STS AssumeRole 获取临时凭证
+	CredentialsTypeAssumeRole CredentialsType = "assume_role"
+
+	// CredentialsTypeWebIdentity 使用 OIDC Token 文件调用 STS AssumeRoleWithWebIdentity 获取临时凭证，
+	// 例如 EKS IRSA 或 Kubernetes ServiceAccount Token
+	CredentialsTypeWebIdentity CredentialsType = "web_identity"
+
+	// CredentialsTypeIAM 从实例元数据服务获取临时凭证，支持 EC2 IMDSv2、ECS 及 EKS Pod Identity
+	CredentialsTypeIAM CredentialsType = "iam"
+
+	// CredentialsTypeChain 依次尝试 Chain 中的凭证，使用第一个成功获取的凭证
+	CredentialsTypeChain CredentialsType = "chain"
+)
+
+var CredentialsTypes = []CredentialsType{
+	CredentialsTypeStatic,
+	CredentialsTypeEnv,
+	CredentialsTypeFile,
+	CredentialsTypeAssumeRole,
+	CredentialsTypeWebIdentity,
+	CredentialsTypeIAM,
+	CredentialsTypeChain,
+}
+
+// CredentialsConfig 访问凭证配置
+//
+// 临时凭证在过期前自动刷新（剩余 20% 有效期时），SessionToken 会同时用于请求及预签名链接
+type CredentialsConfig struct {
+	Type CredentialsType `json:"type"`
+
+	// static: required
+	// assume_role: required, long-term credentials used to call STS
+	AccessKey    string `json:"access_key"`
+	SecretKey    string `json:"secret_key"`
+	SessionToken string `json:"session_token"`
+
+	// file: optional, default to $AWS_SHARED_CREDENTIALS_FILE or ~/.aws/credentials
+	File string `json:"file"`
+
+	// file: optional, default to $AWS_PROFILE or "default"
+	Profile string `json:"profile"`
+
+	// assume_role, web_identity: optional, default to https://sts.amazonaws.com
+	STSEndpoint string `json:"sts_endpoint"`
+
+	// assume_role: optional, required by AWS STS
+	// web_identity: optional, default to $AWS_ROLE_ARN
+	RoleARN string `json:"role_arn"`
+
+	// assume_role: optional
+	RoleSessionName string `json:"role_session_name"`
+	ExternalID      string `json:"external_id"`
+	Region          string `json:"region"`
+
+	// assume_role: optional, default to 3600
+	DurationSeconds int `json:"duration_seconds"`
+
+	// web_identity: optional, default to $AWS_WEB_IDENTITY_TOKEN_FILE
+	WebIdentityTokenFile string `json:"web_identity_token_file"`
+
+	// iam: optional, default to EC2 instance metadata service, or ECS / EKS endpoint by environment variables
+	IAMEndpoint string `json:"iam_endpoint"`
+
+	// chain: required
+	Chain []*CredentialsConfig `json:"chain"`
+}
+
+func (c *CredentialsConfig) Validate() error {
+	switch c.Type {
+	case CredentialsTypeStatic:
+		if c.AccessKey == "" || c.SecretKey == "" {
+			return &ConfigError{Field: "credentials.access_key", Reason: "and secret_key is required"}
+		}
+	case CredentialsTypeAssumeRole:
+		if c.AccessKey == "" || c.SecretKey == "" {
+			return &ConfigError{Field: "credentials.access_key", Reason: "and secret_key is required for assume_role"}
+		}
+	case CredentialsTypeEnv, CredentialsTypeFile, CredentialsTypeWebIdentity, CredentialsTypeIAM:
+	case CredentialsTypeChain:
+		if len(c.Chain) == 0 {
+			return &ConfigError{Field: "credentials.chain", Reason: "is required"}
+		}
+		for _, sub := range c.Chain {
+			if err := sub.Validate(); err != nil {
+				return err
+			}
+		}
+	case "":
+		return &ConfigError{Field: "credentials.type", Reason: "is required"}
+	default:
+		return &ConfigError{Field: "credentials.type", Reason: "is unknown: " + string(c.Type)}
+	}
+
+	return nil
+}
+
+// NewCredentials 根据配置创建访问凭证，凭证在首次使用时获取，过期前自动刷新
+func NewCredentials(cfg *CredentialsConfig) (*credentials.Credentials, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	p, err := newCredentialsProvider(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return credentials.New(p), nil
+}
+
+func newCredentialsProvider(cfg *CredentialsConfig) (credentials.Provider, error) {
+	switch cfg.Type {
+	case CredentialsTypeStatic:
+		return &credentials.Static{Value: credentials.Value{
+			AccessKeyID:     cfg.AccessKey,
+			SecretAccessKey: cfg.SecretKey,
+			SessionToken:    cfg.SessionToken,
+			SignerType:      credentials.SignatureV4,
+		}}, nil
+
+	case CredentialsTypeEnv:
+		return &credentials.Chain{Providers: []credentials.Provider{
+			&credentials.EnvAWS{},
+			&credentials.EnvMinio{},
+		}}, nil
+
+	case CredentialsTypeFile:
+		return &credentials.FileAWSCredentials{
+			Filename: cfg.File,
+			Profile:  cfg.Profile,
+		}, nil
+
+	case CredentialsTypeAssumeRole:
+		return &credentials.STSAssumeRole{
+			STSEndpoint: cfg.stsEndpoint(),
+			Options: credentials.STSAssumeRoleOptions{
+				AccessKey:       cfg.AccessKey,
+				SecretKey:       cfg.SecretKey,
+				SessionToken:    cfg.SessionToken,
+				Location:        cfg.Region,
+				DurationSeconds: cfg.DurationSeconds,
+				RoleARN:         cfg.RoleARN,
+				RoleSessionName: cfg.RoleSessionName,
+				ExternalID:      cfg.ExternalID,
+			},
+		}, nil
+
+	case CredentialsTypeWebIdentity:
+		roleARN := cfg.RoleARN
+		if roleARN == "" {
+			roleARN = os.Getenv("AWS_ROLE_ARN")
+		}
+
+		return &credentials.STSWebIdentity{
+			STSEndpoint: cfg.stsEndpoint(),
+			RoleARN:     roleARN,
+			GetWebIDTokenExpiry: func() (*credentials.WebIdentityToken, error) {
+				// missing token file fails on retrieval instead of creation, so chain falls through to next provider
+				tokenFile := cfg.WebIdentityTokenFile
+				if tokenFile == "" {
+					tokenFile = os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE")
+				}
+				if tokenFile == "" {
+					return nil, fmt.Errorf("%w: web_identity_token_file is required when AWS_WEB_IDENTITY_TOKEN_FILE is not set", ErrInvalidCredentials)
+				}
+
+				// token file is rotated by kubelet, read it on every refresh
+				token, err := os.ReadFile(tokenFile)
+				if err != nil {
+					return nil, fmt.Errorf("failed to read web identity token: %w", err)
+				}
+				return &credentials.WebIdentityToken{Token: strings.TrimSpace(string(token))}, nil
+			},
+		}, nil
+
+	case CredentialsTypeIAM:
+		return &credentials.IAM{Endpoint: cfg.IAMEndpoint}, nil
+
+	case CredentialsTypeChain:
+		providers := make([]credentials.Provider, 0, len(cfg.Chain))
+		for _, sub := range cfg.Chain {
+			p, err := newCredentialsProvider(sub)
+			if err != nil {
+				return nil, err
+			}
+			providers = append(providers, p)
+		}
+		return &credentials.Chain{Providers: providers}, nil
+
+	default:
+		return nil, &ConfigError{Field: "credentials.type", Reason: "is unknown: " + string(cfg.Type)}
+	}
+}
+
+// ResolveCredentials 按 creds、cfg、静态 AccessKey 及 SecretKey 的优先级返回访问凭证
+func ResolveCredentials(creds *credentials.Credentials, cfg *CredentialsConfig, accessKey, secretKey string) (*credentials.Credentials, error) {
+	if creds != nil {
+		return creds, nil
+	}
+	if cfg != nil {
+		return NewCredentials(cfg)
+	}
+	return credentials.NewStaticV4(accessKey, secretKey, ""), nil
+}
+
+// LongTermKeys 返回长期有效的 AccessKey 及 SecretKey，供七牛等不支持 SessionToken 的签名方式使用
+//
+// creds 非空时随 ctx 获取凭证，获取到临时凭证时返回 ErrInvalidCredentials
+func LongTermKeys(ctx context.Context, creds *credentials.Credentials, accessKey, secretKey string) (string, string, error) {
+	if creds == nil {
+		return accessKey, secretKey, nil
+	}
+
+	v, err := creds.GetWithContext(NewCredContext(ctx))
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %w", ErrInvalidCredentials, err)
+	}
+	if v.SessionToken != "" {
+		return "", "", fmt.Errorf("%w: temporary credentials with session token are not supported, use static access_key and secret_key", ErrInvalidCredentials)
+	}
+	return v.AccessKeyID, v.SecretAccessKey, nil
+}
+
+// NewCredContext 返回绑定 ctx 的凭证上下文，STS、IMDS 等刷新请求随 ctx 取消
+func NewCredContext(ctx context.Context) *credentials.CredContext {
+	return &credentials.CredContext{
+		Client: &http.Client{Transport: &ctxTransport{ctx: ctx, base: http.DefaultTransport}},
+	}
+}
+
+// ctxTransport 将 ctx 注入 credentials 内部构造的请求，这些请求不携带调用方的 ctx
+type ctxTransport struct {
+	ctx  context.Context
+	base http.RoundTripper
+}
+
+func (t *ctxTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.base.RoundTrip(req.WithContext(t.ctx))
+}
+
+func (c *CredentialsConfig) stsEndpoint() string {
+	if c.STSEndpoint == "" {
+		return credentials.DefaultSTSRoleEndpoint
+	}
+	return c.STSEndpoint
+}
@@ -0,0 +1,60 @@
+package s3common_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ix64/s3-go/s3common"
+)
+
+func TestNewCredContext(t *testing.T) {
+	// STS endpoint never responds until test finishes
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	creds, err := credentials.NewSTSAssumeRole(srv.URL, credentials.STSAssumeRoleOptions{AccessKey: "ak", SecretKey: "sk"})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = creds.GetWithContext(s3common.NewCredContext(ctx))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestNewCredentials_WebIdentityWithoutTokenFile(t *testing.T) {
+	t.Setenv("AWS_WEB_IDENTITY_TOKEN_FILE", "")
+
+	creds, err := s3common.NewCredentials(&s3common.CredentialsConfig{Type: s3common.CredentialsTypeWebIdentity})
+	require.NoError(t, err)
+
+	_, err = creds.GetWithContext(s3common.NewCredContext(context.Background()))
+	assert.ErrorIs(t, err, s3common.ErrInvalidCredentials)
+
+	// chain falls through to the next provider
+	creds, err = s3common.NewCredentials(&s3common.CredentialsConfig{
+		Type: s3common.CredentialsTypeChain,
+		Chain: []*s3common.CredentialsConfig{
+			{Type: s3common.CredentialsTypeWebIdentity},
+			{Type: s3common.CredentialsTypeStatic, AccessKey: "ak", SecretKey: "sk"},
+		},
+	})
+	require.NoError(t, err)
+
+	v, err := creds.GetWithContext(s3common.NewCredContext(context.Background()))
+	require.NoError(t, err)
+	assert.Equal(t, "ak", v.AccessKeyID)
+}
@@ -0,0 +1,87 @@
+package s3common
+
+import (
+	"encoding/base64"
+
+	"github.com/minio/minio-go/v7/pkg/encrypt"
+)
+
+type EncryptionType string
+
+const (
+	// EncryptionTypeS3 SSE-S3，使用对象存储管理的密钥加密
+	EncryptionTypeS3 EncryptionType = "sse-s3"
+
+	// EncryptionTypeKMS SSE-KMS，使用 KMS 管理的密钥加密
+	EncryptionTypeKMS EncryptionType = "sse-kms"
+
+	// EncryptionTypeC SSE-C，使用客户提供的密钥加密，读取及复制时需要提供同一密钥
+	EncryptionTypeC EncryptionType = "sse-c"
+)
+
+var EncryptionTypes = []EncryptionType{
+	EncryptionTypeS3,
+	EncryptionTypeKMS,
+	EncryptionTypeC,
+}
+
+// EncryptionConfig 服务端加密配置
+type EncryptionConfig struct {
+	Type EncryptionType `json:"type"`
+
+	// sse-kms: optional, default to the default KMS key of bucket
+	KMSKeyID string `json:"kms_key_id"`
+
+	// sse-kms: optional, encryption context
+	KMSContext map[string]string `json:"kms_context"`
+
+	// sse-c: required, base64 encoded 256-bit key
+	CustomerKey string `json:"customer_key"`
+}
+
+func (c *EncryptionConfig) Validate() error {
+	switch c.Type {
+	case EncryptionTypeS3, EncryptionTypeKMS:
+	case EncryptionTypeC:
+		key, err := base64.StdEncoding.DecodeString(c.CustomerKey)
+		if err != nil {
+			return &ConfigError{Field: "encryption.customer_key", Reason: "is invalid: " + err.Error()}
+		}
+		if len(key) != 32 {
+			return &ConfigError{Field: "encryption.customer_key", Reason: "must be 256 bit long"}
+		}
+	case "":
+		return &ConfigError{Field: "encryption.type", Reason: "is required"}
+	default:
+		return &ConfigError{Field: "encryption.type", Reason: "is unknown: " + string(c.Type)}
+	}
+
+	return nil
+}
+
+// NewServerSide 根据配置创建服务端加密参数，cfg 为 nil 时返回 nil，即不指定加密方式
+func NewServerSide(cfg *EncryptionConfig) (encrypt.ServerSide, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	switch cfg.Type {
+	case EncryptionTypeS3:
+		return encrypt.NewSSE(), nil
+	case EncryptionTypeKMS:
+		var context any
+		if len(cfg.KMSContext) > 0 {
+			context = cfg.KMSContext
+		}
+		return encrypt.NewSSEKMS(cfg.KMSKeyID, context)
+	case EncryptionTypeC:
+		key, _ := base64.StdEncoding.DecodeString(cfg.CustomerKey) // validated
+		return encrypt.NewSSEC(key)
+	default:
+		return nil, &ConfigError{Field: "encryption.type", Reason: "is unknown: " + string(cfg.Type)}
+	}
+}
@@ -0,0 +1,157 @@
+package s3common
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/minio/minio-go/v7"
+)
+
+var (
+	// ErrNotFound 对象、Bucket、分片上传或版本不存在
+	ErrNotFound = errors.New("not found")
+
+	// ErrAccessDenied 无权访问
+	ErrAccessDenied = errors.New("access denied")
+
+	// ErrInvalidCredentials 访问凭证无效或签名错误
+	ErrInvalidCredentials = errors.New("invalid credentials")
+
+	// ErrPreconditionFailed 条件请求 If-Match / If-Unmodified-Since 未满足
+	ErrPreconditionFailed = errors.New("precondition failed")
+
+	// ErrNotModified 条件请求 If-None-Match / If-Modified-Since 未满足，对象未修改
+	ErrNotModified = errors.New("not modified")
+
+	// ErrInvalidRange 请求的范围超出对象大小
+	ErrInvalidRange = errors.New("invalid range")
+
+	// ErrConflict 与正在进行的其他操作冲突
+	ErrConflict = errors.New("conflict")
+
+	// ErrInvalidArgument 请求参数错误
+	ErrInvalidArgument = errors.New("invalid argument")
+
+	// ErrChecksumMismatch 内容的 MD5、sha256 或 ETag 与预期不一致
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+
+	// ErrEntityTooLarge 上传的内容超过允许的大小
+	ErrEntityTooLarge = errors.New("entity too large")
+
+	// ErrEntityTooSmall 上传的内容小于允许的大小
+	ErrEntityTooSmall = errors.New("entity too small")
+
+	// ErrObjectLocked 对象受 Object Lock 保留期或法律保留保护，无法删除或覆盖
+	ErrObjectLocked = errors.New("object locked")
+
+	// ErrNotSupported 供应商或当前配置不支持该操作
+	ErrNotSupported = errors.New("not supported")
+
+	// ErrInvalidConfig 配置错误，具体字段参见 ConfigError
+	ErrInvalidConfig = errors.New("invalid config")
+)
+
+// ConfigError 配置校验错误，errors.Is(err, ErrInvalidConfig) 为 true
+type ConfigError struct {
+	// Field 配置字段的 JSON 名称，例如 "endpoint"
+	Field string
+
+	// Reason 错误原因，例如 "is required"
+	Reason string
+}
+
+func (e *ConfigError) Error() string {
+	return e.Field + " " + e.Reason
+}
+
+func (e *ConfigError) Is(target error) bool {
+	return target == ErrInvalidConfig
+}
+
+// errorCodes S3 错误码对应的错误分类
+var errorCodes = map[string]error{
+	"NoSuchKey":     ErrNotFound,
+	"NoSuchBucket":  ErrNotFound,
+	"NoSuchUpload":  ErrNotFound,
+	"NoSuchVersion": ErrNotFound,
+
+	"AccessDenied":      ErrAccessDenied,
+	"AllAccessDisabled": ErrAccessDenied,
+	"AccountProblem":    ErrAccessDenied,
+
+	"InvalidAccessKeyId":           ErrInvalidCredentials,
+	"SignatureDoesNotMatch":        ErrInvalidCredentials,
+	"ExpiredToken":                 ErrInvalidCredentials,
+	"InvalidToken":                 ErrInvalidCredentials,
+	"AuthorizationHeaderMalformed": ErrInvalidCredentials,
+	"RequestTimeTooSkewed":         ErrInvalidCredentials,
+
+	"PreconditionFailed": ErrPreconditionFailed,
+	"InvalidRange":       ErrInvalidRange,
+
+	"Conflict":         ErrConflict,
+	"OperationAborted": ErrConflict,
+
+	"BadDigest":                 ErrChecksumMismatch,
+	"InvalidDigest":             ErrChecksumMismatch,
+	"XAmzContentSHA256Mismatch": ErrChecksumMismatch,
+
+	"EntityTooLarge": ErrEntityTooLarge,
+	"EntityTooSmall": ErrEntityTooSmall,
+
+	"InvalidArgument":  ErrInvalidArgument,
+	"InvalidPart":      ErrInvalidArgument,
+	"InvalidPartOrder": ErrInvalidArgument,
+	"MalformedXML":     ErrInvalidArgument,
+
+	"ObjectLocked": ErrObjectLocked,
+
+	"ObjectLockConfigurationNotFoundError": ErrNotFound,
+	"NoSuchObjectLockConfiguration":        ErrNotFound,
+
+	"NotImplemented":  ErrNotSupported,
+	"APINotSupported": ErrNotSupported,
+}
+
+// errorStatusCodes 无法通过错误码分类时，按 HTTP 状态码分类
+var errorStatusCodes = map[int]error{
+	http.StatusNotModified:                  ErrNotModified,
+	http.StatusBadRequest:                   ErrInvalidArgument,
+	http.StatusForbidden:                    ErrAccessDenied,
+	http.StatusNotFound:                     ErrNotFound,
+	http.StatusConflict:                     ErrConflict,
+	http.StatusPreconditionFailed:           ErrPreconditionFailed,
+	http.StatusRequestEntityTooLarge:        ErrEntityTooLarge,
+	http.StatusRequestedRangeNotSatisfiable: ErrInvalidRange,
+	http.StatusNotImplemented:               ErrNotSupported,
+}
+
+// ConvertError 将 minio 返回的错误转换为对应的错误分类，可使用 errors.Is 判断
+// 原始的 minio.ErrorResponse 仍可通过 errors.As 获取
+func ConvertError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var resp minio.ErrorResponse
+	if !errors.As(err, &resp) {
+		return err
+	}
+
+	kind, ok := errorCodes[resp.Code]
+	switch {
+	case isMissingObjectLockMessage(resp.Message):
+		kind, ok = ErrNotFound, true
+	case (resp.Code == "AccessDenied" || resp.StatusCode == http.StatusForbidden) && isObjectLockedMessage(resp.Message):
+		kind, ok = ErrObjectLocked, true
+	}
+	if !ok {
+		kind, ok = errorStatusCodes[resp.StatusCode]
+	}
+	if !ok || errors.Is(err, kind) {
+		return err
+	}
+
+	return fmt.Errorf("%w: %w", kind, err)
+}
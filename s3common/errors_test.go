@@ -0,0 +1,113 @@
+package s3common_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ix64/s3-go/s3common"
+)
+
+func TestConvertError(t *testing.T) {
+	for name, tc := range map[string]struct {
+		resp minio.ErrorResponse
+		kind error
+	}{
+		"NoSuchKey": {
+			resp: minio.ErrorResponse{Code: "NoSuchKey", StatusCode: http.StatusNotFound},
+			kind: s3common.ErrNotFound,
+		},
+		"AccessDenied": {
+			resp: minio.ErrorResponse{Code: "AccessDenied", StatusCode: http.StatusForbidden, Message: "Access Denied"},
+			kind: s3common.ErrAccessDenied,
+		},
+		"SignatureDoesNotMatch": {
+			resp: minio.ErrorResponse{Code: "SignatureDoesNotMatch", StatusCode: http.StatusForbidden},
+			kind: s3common.ErrInvalidCredentials,
+		},
+		"PreconditionFailed": {
+			resp: minio.ErrorResponse{Code: "PreconditionFailed", StatusCode: http.StatusPreconditionFailed},
+			kind: s3common.ErrPreconditionFailed,
+		},
+		"BadDigest": {
+			resp: minio.ErrorResponse{Code: "BadDigest", StatusCode: http.StatusBadRequest},
+			kind: s3common.ErrChecksumMismatch,
+		},
+		"EntityTooSmall": {
+			resp: minio.ErrorResponse{Code: "EntityTooSmall", StatusCode: http.StatusBadRequest},
+			kind: s3common.ErrEntityTooSmall,
+		},
+		"StatusNotModified": {
+			// HEAD responses have no body, code is empty
+			resp: minio.ErrorResponse{StatusCode: http.StatusNotModified},
+			kind: s3common.ErrNotModified,
+		},
+		"StatusNotFound": {
+			resp: minio.ErrorResponse{StatusCode: http.StatusNotFound},
+			kind: s3common.ErrNotFound,
+		},
+		"StatusUnknownCode": {
+			resp: minio.ErrorResponse{Code: "VendorSpecificError", StatusCode: http.StatusConflict},
+			kind: s3common.ErrConflict,
+		},
+		"StatusRangeNotSatisfiable": {
+			resp: minio.ErrorResponse{StatusCode: http.StatusRequestedRangeNotSatisfiable},
+			kind: s3common.ErrInvalidRange,
+		},
+		"ObjectLocked": {
+			resp: minio.ErrorResponse{Code: "ObjectLocked", StatusCode: http.StatusBadRequest, Message: "Object is WORM protected and cannot be overwritten"},
+			kind: s3common.ErrObjectLocked,
+		},
+		"ObjectLockedAccessDenied": {
+			resp: minio.ErrorResponse{Code: "AccessDenied", StatusCode: http.StatusForbidden, Message: "Access Denied because object protected by object lock."},
+			kind: s3common.ErrObjectLocked,
+		},
+		"ObjectLockMessageInvalidArgument": {
+			resp: minio.ErrorResponse{Code: "InvalidArgument", StatusCode: http.StatusBadRequest, Message: "x-amz-object-lock-mode and x-amz-object-lock-retain-until-date must both be supplied"},
+			kind: s3common.ErrInvalidArgument,
+		},
+		"ObjectLockConfigurationNotFound": {
+			resp: minio.ErrorResponse{Code: "ObjectLockConfigurationNotFoundError", StatusCode: http.StatusNotFound, Message: "Object Lock configuration does not exist for this bucket"},
+			kind: s3common.ErrNotFound,
+		},
+		"MissingObjectLockConfiguration": {
+			resp: minio.ErrorResponse{Code: "InvalidRequest", StatusCode: http.StatusBadRequest, Message: "Bucket is missing Object Lock Configuration"},
+			kind: s3common.ErrNotFound,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// minio wraps ErrorResponse while retrying
+			err := s3common.ConvertError(fmt.Errorf("request failed: %w", tc.resp))
+			assert.ErrorIs(t, err, tc.kind)
+			if tc.kind != s3common.ErrObjectLocked {
+				assert.NotErrorIs(t, err, s3common.ErrObjectLocked)
+			}
+
+			var resp minio.ErrorResponse
+			if assert.ErrorAs(t, err, &resp) {
+				assert.Equal(t, tc.resp.Code, resp.Code)
+				assert.Equal(t, tc.resp.StatusCode, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestConvertErrorPassThrough(t *testing.T) {
+	assert.NoError(t, s3common.ConvertError(nil))
+
+	// not an S3 error response
+	err := errors.New("connection refused")
+	assert.Same(t, err, s3common.ConvertError(err))
+
+	// unknown code and status
+	resp := minio.ErrorResponse{Code: "SlowDown", StatusCode: http.StatusServiceUnavailable}
+	assert.Equal(t, error(resp), s3common.ConvertError(resp))
+
+	// already classified
+	classified := fmt.Errorf("%w: %w", s3common.ErrNotFound, minio.ErrorResponse{Code: "NoSuchKey", StatusCode: http.StatusNotFound})
+	assert.Same(t, classified, s3common.ConvertError(classified))
+}
@@ -0,0 +1,84 @@
+package s3common
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+type RetentionMode string
+
+const (
+	// RetentionModeGovernance 拥有 s3:BypassGovernanceRetention 权限的用户可提前删除或缩短保留期
+	RetentionModeGovernance RetentionMode = "GOVERNANCE"
+
+	// RetentionModeCompliance 保留期内任何用户（包括 root）都无法删除对象或缩短保留期
+	RetentionModeCompliance RetentionMode = "COMPLIANCE"
+)
+
+var RetentionModes = []RetentionMode{
+	RetentionModeGovernance,
+	RetentionModeCompliance,
+}
+
+func (m RetentionMode) IsValid() bool {
+	return m == RetentionModeGovernance || m == RetentionModeCompliance
+}
+
+// RetentionConfig 上传时强制应用的 Object Lock 保留策略，bucket 需要开启 Object Lock
+type RetentionConfig struct {
+	// optional, required with Days
+	Mode RetentionMode `json:"mode"`
+
+	// optional, required with Mode, retain until upload time plus Days
+	Days int `json:"days"`
+
+	// optional, enable legal hold, which has no expiration
+	LegalHold bool `json:"legal_hold"`
+}
+
+func (c *RetentionConfig) Validate() error {
+	switch {
+	case c.Mode == "" && c.Days == 0:
+		if !c.LegalHold {
+			return &ConfigError{Field: "retention.mode", Reason: "or legal_hold is required"}
+		}
+	case !c.Mode.IsValid():
+		return &ConfigError{Field: "retention.mode", Reason: "is unknown: " + string(c.Mode)}
+	case c.Days <= 0:
+		return &ConfigError{Field: "retention.days", Reason: "must be positive"}
+	}
+
+	return nil
+}
+
+// RetainUntil 返回从 now 起算的保留截止时间，未设置 Mode 时返回零值
+func (c *RetentionConfig) RetainUntil(now time.Time) time.Time {
+	if c.Mode == "" {
+		return time.Time{}
+	}
+	return now.AddDate(0, 0, c.Days).UTC().Truncate(time.Second)
+}
+
+// Marshal 将保留策略写入请求头
+func (c *RetentionConfig) Marshal(h http.Header, now time.Time) {
+	if c.Mode != "" {
+		h.Set("X-Amz-Object-Lock-Mode", string(c.Mode))
+		h.Set("X-Amz-Object-Lock-Retain-Until-Date", c.RetainUntil(now).Format(time.RFC3339))
+	}
+	if c.LegalHold {
+		h.Set("X-Amz-Object-Lock-Legal-Hold", "ON")
+	}
+}
+
+// isObjectLockedMessage 部分服务以 AccessDenied 返回对象锁定错误，只能通过错误信息与普通的无权访问区分
+func isObjectLockedMessage(message string) bool {
+	message = strings.ToLower(message)
+	return strings.Contains(message, "object lock") || strings.Contains(message, "worm protected")
+}
+
+// isMissingObjectLockMessage S3 对未开启 Object Lock 的 bucket 设置保留时以 InvalidRequest 返回
+// "Bucket is missing Object Lock Configuration"
+func isMissingObjectLockMessage(message string) bool {
+	return strings.Contains(strings.ToLower(message), "missing object lock configuration")
+}
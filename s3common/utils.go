@@ -1,9 +1,12 @@
 package s3common
 
 import (
+	"net"
 	"net/url"
 	"strconv"
 	"strings"
+
+	"github.com/minio/minio-go/v7/pkg/s3utils"
 )
 
 func ComposeContentDisposition(filename string) string {
@@ -17,3 +20,35 @@ func ComposeContentDisposition(filename string) string {
 		"filename*=UTF-8''" + url.QueryEscape(filename),
 	}, "; ")
 }
+
+// SplitCNAMEHost 将自定义域名拆分为虚拟主机风格的 bucket 及 endpoint host
+//
+// 虚拟主机风格下 bucket 只出现在 Host 中，SDK 以 bucket.endpoint 访问的即为自定义域名本身，
+// 签名使用自定义域名且路径中不包含 bucket。例如 files.example.com 拆分为 files 及 example.com
+func SplitCNAMEHost(host string) (bucket string, endpointHost string, err error) {
+	hostname, port := host, ""
+	if h, p, err := net.SplitHostPort(host); err == nil {
+		hostname, port = h, p
+	}
+
+	if net.ParseIP(hostname) == nil {
+		// bucket name requires at least 3 characters, try longer prefix for short label like s3.example.com
+		for i := strings.IndexByte(hostname, '.'); i > 0; {
+			bucket, endpointHost = hostname[:i], hostname[i+1:]
+			if endpointHost != "" && s3utils.CheckValidBucketName(bucket) == nil {
+				if port != "" {
+					endpointHost = net.JoinHostPort(endpointHost, port)
+				}
+				return bucket, endpointHost, nil
+			}
+
+			next := strings.IndexByte(hostname[i+1:], '.')
+			if next < 0 {
+				break
+			}
+			i += next + 1
+		}
+	}
+
+	return "", "", &ConfigError{Field: "endpoint", Reason: "must be a custom domain when bucket_lookup is cname"}
+}
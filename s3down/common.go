@@ -1,9 +1,15 @@
 package s3down
 
 import (
+	"fmt"
 	"net/url"
 	"path"
+	"strings"
 	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/ix64/s3-go/s3common"
 )
 
 func composeObjectURL(base *url.URL, prefix, remotePath string) *url.URL {
@@ -17,3 +23,12 @@ func composeObjectURL(base *url.URL, prefix, remotePath string) *url.URL {
 }
 
 var TimezoneCST = time.FixedZone("CST", 8*60*60)
+
+// timeNow 及 newNonce 在测试中替换为固定值，以校验固定的签名结果
+var (
+	timeNow  = time.Now
+	newNonce = func() string { return strings.ReplaceAll(uuid.NewString(), "-", "") }
+)
+
+// errVersionIDNotSupported CDN 缓存键通常不包含 Query String，无法区分对象版本
+var errVersionIDNotSupported = fmt.Errorf("%w: version id is not supported by CDN generator", s3common.ErrNotSupported)
@@ -0,0 +1,16 @@
+package s3down
+
+import (
+	"testing"
+	"time"
+)
+
+// SetClock 固定签名使用的当前时间及随机数，测试结束时恢复
+func SetClock(t testing.TB, now time.Time, nonce string) {
+	origNow, origNonce := timeNow, newNonce
+	timeNow = func() time.Time { return now }
+	newNonce = func() string { return nonce }
+	t.Cleanup(func() {
+		timeNow, newNonce = origNow, origNonce
+	})
+}
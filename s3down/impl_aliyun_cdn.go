@@ -4,7 +4,6 @@ import (
 	"context"
 	"crypto/md5"
 	"encoding/hex"
-	"errors"
 	"fmt"
 	"net/url"
 	"path"
@@ -74,15 +73,15 @@ type GeneratorAliyunCDNConfig struct {
 
 func (c *GeneratorAliyunCDNConfig) Validate() error {
 	if c.Endpoint == "" {
-		return errors.New("endpoint is required")
+		return &s3common.ConfigError{Field: "endpoint", Reason: "is required"}
 	}
 
 	if !slices.Contains(AliyunCDNAuthModes, c.AuthMode) {
-		return fmt.Errorf("unknown auth mode: %s", c.AuthMode)
+		return &s3common.ConfigError{Field: "auth_mode", Reason: "is unknown: " + string(c.AuthMode)}
 	}
 
 	if c.AuthMode != AliyunCDNAuthModeNone && c.AuthKey == "" {
-		return errors.New("auth key is required")
+		return &s3common.ConfigError{Field: "auth_key", Reason: "is required"}
 	}
 
 	return nil
@@ -100,15 +99,11 @@ func NewGeneratorAliyunCDN(cfg *GeneratorAliyunCDNConfig) (*GeneratorAliyunCDN,
 
 	u, err := url.Parse(cfg.Endpoint)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse endpoint: %w", err)
+		return nil, &s3common.ConfigError{Field: "endpoint", Reason: "is invalid: " + err.Error()}
 	}
 
 	if u.Scheme != "http" && u.Scheme != "https" {
-		return nil, errors.New("endpoint scheme must be http or https")
-	}
-
-	if cfg.AuthMode != AliyunCDNAuthModeNone && cfg.AuthKey == "" {
-		return nil, errors.New("auth key is required")
+		return nil, &s3common.ConfigError{Field: "endpoint", Reason: "scheme must be http or https"}
 	}
 
 	return &GeneratorAliyunCDN{
@@ -119,6 +114,10 @@ func NewGeneratorAliyunCDN(cfg *GeneratorAliyunCDNConfig) (*GeneratorAliyunCDN,
 }
 
 func (d *GeneratorAliyunCDN) GenerateDownload(_ context.Context, params *GenerateParams) (*url.URL, error) {
+	if params.VersionID != "" {
+		return nil, errVersionIDNotSupported
+	}
+
 	query := make(url.Values)
 
 	if !d.cfg.DisableResponseContentType && params.ContentType != "" {
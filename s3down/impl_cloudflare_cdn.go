@@ -0,0 +1,187 @@
+package s3down
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ix64/s3-go/s3common"
+)
+
+type CloudflareCDNAuthMode string
+
+const (
+	// CloudflareCDNAuthModeNone Cloudflare 无鉴权
+	CloudflareCDNAuthModeNone = ""
+
+	// CloudflareCDNAuthModeWAF Cloudflare WAF Token 鉴权，URL 参数为 verify=<timestamp>-<mac>
+	// 参考: https://developers.cloudflare.com/waf/custom-rules/use-cases/configure-token-authentication/
+	//
+	// 控制台配置要求（WAF 自定义规则表达式）
+	//   not is_timed_hmac_valid_v0("<AuthKey>", http.request.uri, <TTL>, http.request.timestamp.sec, 8)
+	// - 签名内容：URL Path 及 Query（不含 verify 参数）+ 时间戳
+	// - 分隔符长度：8，即 "?verify=" 或 "&verify="
+	CloudflareCDNAuthModeWAF = "waf"
+
+	// CloudflareCDNAuthModeWorker Cloudflare Workers 签名请求，URL 参数为 mac=<mac>&expiry=<expiry>
+	// 参考: https://developers.cloudflare.com/workers/examples/signing-requests/
+	//
+	// Worker 校验要求
+	// - 签名内容：URL Path + 过期时间（Unix 时间戳，秒）
+	// - 签名算法：HMAC-SHA256，base64 编码
+	CloudflareCDNAuthModeWorker = "worker"
+)
+
+var CloudflareCDNAuthModes = []CloudflareCDNAuthMode{
+	CloudflareCDNAuthModeNone,
+	CloudflareCDNAuthModeWAF,
+	CloudflareCDNAuthModeWorker,
+}
+
+type GeneratorCloudflareCDNConfig struct {
+	GeneratorConfigCommon
+
+	// Endpoint 填写 Cloudflare 代理的域名，例如 R2 自定义域名：https://files.example.com
+	Endpoint string `json:"endpoint"`
+
+	// AuthMode 鉴权方式
+	AuthMode CloudflareCDNAuthMode `json:"auth_mode"`
+
+	// AuthKey 填写 WAF 规则或 Worker 中的 HMAC 密钥
+	AuthKey string `json:"auth_key"`
+
+	// TTL 仅 waf 模式，填写 WAF 规则中的有效时长（秒）
+	// 设置后时间戳为过期时间减去 TTL，链接在 ExpireIn 后失效，ExpireIn 不能超过 TTL；
+	// 未设置时时间戳为当前时间，链接有效期由 WAF 规则决定
+	TTL int64 `json:"ttl"`
+}
+
+func (c *GeneratorCloudflareCDNConfig) Validate() error {
+	if c.Endpoint == "" {
+		return &s3common.ConfigError{Field: "endpoint", Reason: "is required"}
+	}
+
+	if !slices.Contains(CloudflareCDNAuthModes, c.AuthMode) {
+		return &s3common.ConfigError{Field: "auth_mode", Reason: "is unknown: " + string(c.AuthMode)}
+	}
+
+	if c.AuthMode != CloudflareCDNAuthModeNone && c.AuthKey == "" {
+		return &s3common.ConfigError{Field: "auth_key", Reason: "is required"}
+	}
+
+	if c.TTL < 0 {
+		return &s3common.ConfigError{Field: "ttl", Reason: "must not be negative"}
+	}
+
+	return nil
+}
+
+type GeneratorCloudflareCDN struct {
+	endpoint *url.URL
+	cfg      *GeneratorCloudflareCDNConfig
+}
+
+func NewGeneratorCloudflareCDN(cfg *GeneratorCloudflareCDNConfig) (*GeneratorCloudflareCDN, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, &s3common.ConfigError{Field: "endpoint", Reason: "is invalid: " + err.Error()}
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, &s3common.ConfigError{Field: "endpoint", Reason: "scheme must be http or https"}
+	}
+
+	return &GeneratorCloudflareCDN{
+		cfg:      cfg,
+		endpoint: u,
+	}, nil
+}
+
+func (d *GeneratorCloudflareCDN) GenerateDownload(_ context.Context, params *GenerateParams) (*url.URL, error) {
+	if params.VersionID != "" {
+		return nil, errVersionIDNotSupported
+	}
+
+	query := make(url.Values)
+
+	if !d.cfg.DisableResponseContentType && params.ContentType != "" {
+		query.Set("response-content-type", params.ContentType)
+	}
+
+	if !d.cfg.DisableResponseContentDisposition && params.AttachmentFilename != "" {
+		query.Set("response-content-disposition", s3common.ComposeContentDisposition(params.AttachmentFilename))
+	}
+
+	// timestamp would be in the future, which is rejected by cloudflare
+	if d.cfg.AuthMode == CloudflareCDNAuthModeWAF && d.cfg.TTL > 0 && params.ExpireIn > time.Duration(d.cfg.TTL)*time.Second {
+		return nil, fmt.Errorf("%w: expire in %s exceeds ttl %ds", s3common.ErrInvalidArgument, params.ExpireIn, d.cfg.TTL)
+	}
+
+	u := composeObjectURL(d.endpoint, d.cfg.Prefix, params.RemotePath)
+	u.Path = "/" + strings.TrimPrefix(u.Path, "/") // signed path must be same as request path
+	u.RawQuery = query.Encode()
+
+	switch d.cfg.AuthMode {
+	case CloudflareCDNAuthModeWAF:
+		d.signWAF(u, params.ExpireIn)
+	case CloudflareCDNAuthModeWorker:
+		d.signWorker(u, params.ExpireIn)
+	default:
+		// no-op
+	}
+
+	return u, nil
+}
+
+// signWAF 签名内容为请求 URI 中 verify 参数之前的部分，verify 参数必须位于最后
+func (d *GeneratorCloudflareCDN) signWAF(u *url.URL, expire time.Duration) {
+	signAt := timeNow()
+	if d.cfg.TTL > 0 {
+		signAt = signAt.Add(expire).Add(-time.Duration(d.cfg.TTL) * time.Second)
+	}
+
+	ts := strconv.FormatInt(signAt.Unix(), 10)
+
+	uri := u.EscapedPath()
+	if u.RawQuery != "" {
+		uri += "?" + u.RawQuery
+	}
+
+	sign := d.mac(uri + ts)
+	verify := "verify=" + url.QueryEscape(ts+"-"+sign)
+
+	if u.RawQuery == "" {
+		u.RawQuery = verify
+	} else {
+		u.RawQuery += "&" + verify
+	}
+}
+
+// signWorker 签名内容为 URL Path 及过期时间
+func (d *GeneratorCloudflareCDN) signWorker(u *url.URL, expire time.Duration) {
+	expiry := strconv.FormatInt(timeNow().Add(expire).Unix(), 10)
+
+	sign := d.mac(u.EscapedPath() + expiry)
+
+	query := u.Query()
+	query.Set("mac", sign)
+	query.Set("expiry", expiry)
+	u.RawQuery = query.Encode()
+}
+
+func (d *GeneratorCloudflareCDN) mac(message string) string {
+	h := hmac.New(sha256.New, []byte(d.cfg.AuthKey))
+	h.Write([]byte(message))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
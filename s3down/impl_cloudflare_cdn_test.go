@@ -0,0 +1,89 @@
+package s3down_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ix64/s3-go/s3common"
+	"github.com/ix64/s3-go/s3down"
+)
+
+// TestGeneratorCloudflareCDN 固定时间，校验固定的签名链接
+//
+// 期望值按 is_timed_hmac_valid_v0 及 Worker 示例的签名串格式，以 HMAC-SHA256 独立计算
+func TestGeneratorCloudflareCDN(t *testing.T) {
+	const key = "secret"
+
+	s3down.SetClock(t, time.Unix(1700000000, 0), "")
+
+	newGenerator := func(mode s3down.CloudflareCDNAuthMode, ttl int64) *s3down.GeneratorCloudflareCDN {
+		g, err := s3down.NewGeneratorCloudflareCDN(&s3down.GeneratorCloudflareCDNConfig{
+			GeneratorConfigCommon: s3down.GeneratorConfigCommon{Prefix: "public"},
+			Endpoint:              "https://files.example.com",
+			AuthMode:              mode,
+			AuthKey:               key,
+			TTL:                   ttl,
+		})
+		require.NoError(t, err)
+		return g
+	}
+
+	t.Run("waf", func(t *testing.T) {
+		// sign text: /public/dir/a%20b.txt?response-content-disposition=attachment%3B+filename%3D%22a.txt%22%3B+filename%2A%3DUTF-8%27%27a.txt1700000000
+		u, err := newGenerator(s3down.CloudflareCDNAuthModeWAF, 0).GenerateDownload(context.Background(), &s3down.GenerateParams{
+			RemotePath:         "dir/a b.txt",
+			ExpireIn:           time.Hour,
+			AttachmentFilename: "a.txt",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "https://files.example.com/public/dir/a%20b.txt?response-content-disposition=attachment%3B+filename%3D%22a.txt%22%3B+filename%2A%3DUTF-8%27%27a.txt&verify=1700000000-3bm1ta5SE5dGek14HMzio%2BWsG28OpsjH483xzTxKP2I%3D", u.String())
+	})
+
+	t.Run("waf ttl", func(t *testing.T) {
+		// sign text: /public/a.txt1699917200, timestamp is now + 1h - 86400s
+		u, err := newGenerator(s3down.CloudflareCDNAuthModeWAF, 86400).GenerateDownload(context.Background(), &s3down.GenerateParams{
+			RemotePath: "a.txt",
+			ExpireIn:   time.Hour,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "https://files.example.com/public/a.txt?verify=1699917200-0d2atxIq%2Bo8MPWCGw4sP4N47EC%2BRHLpah5inPIXXlXo%3D", u.String())
+	})
+
+	t.Run("waf expire in exceeds ttl", func(t *testing.T) {
+		_, err := newGenerator(s3down.CloudflareCDNAuthModeWAF, 60).GenerateDownload(context.Background(), &s3down.GenerateParams{
+			RemotePath: "a.txt",
+			ExpireIn:   time.Hour,
+		})
+		assert.ErrorIs(t, err, s3common.ErrInvalidArgument)
+	})
+
+	t.Run("worker", func(t *testing.T) {
+		// sign text: /public/dir/a%20b.txt1700003600
+		u, err := newGenerator(s3down.CloudflareCDNAuthModeWorker, 0).GenerateDownload(context.Background(), &s3down.GenerateParams{
+			RemotePath: "dir/a b.txt",
+			ExpireIn:   time.Hour,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "https://files.example.com/public/dir/a%20b.txt?expiry=1700003600&mac=mIwGZpfp9EiHXjFmlBPGnDLhI6YQKfpVHVkheDbySvI%3D", u.String())
+	})
+
+	t.Run("version id", func(t *testing.T) {
+		_, err := newGenerator(s3down.CloudflareCDNAuthModeNone, 0).GenerateDownload(context.Background(), &s3down.GenerateParams{
+			RemotePath: "a.txt",
+			VersionID:  "v1",
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("validate", func(t *testing.T) {
+		_, err := s3down.NewGeneratorCloudflareCDN(&s3down.GeneratorCloudflareCDNConfig{
+			Endpoint: "https://files.example.com",
+			AuthMode: s3down.CloudflareCDNAuthModeWAF,
+		})
+		assert.Error(t, err)
+	})
+}
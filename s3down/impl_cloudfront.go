@@ -0,0 +1,370 @@
+package s3down
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ix64/s3-go/s3common"
+)
+
+type GeneratorCloudFrontConfig struct {
+	GeneratorConfigCommon
+
+	// Endpoint 填写分配的域名或备用域名，例如：https://d111111abcdef8.cloudfront.net
+	Endpoint string `json:"endpoint"`
+
+	// KeyPairID 填写可信密钥组中公有密钥的 ID，例如：K2JCJMDEHXQW5F
+	// 参考: https://docs.aws.amazon.com/AmazonCloudFront/latest/DeveloperGuide/private-content-trusted-signers.html
+	KeyPairID string `json:"key_pair_id"`
+
+	// PrivateKey 填写 PEM 格式的 RSA 私钥（PKCS#1 或 PKCS#8），与 PrivateKeyFile 二选一
+	PrivateKey string `json:"private_key"`
+
+	// PrivateKeyFile 填写 PEM 格式的 RSA 私钥文件路径
+	PrivateKeyFile string `json:"private_key_file"`
+
+	// IPRange optional, 限制访问者的 IP 地址段，例如：192.0.2.0/24，设置后使用自定义策略签名
+	IPRange string `json:"ip_range"`
+}
+
+func (c *GeneratorCloudFrontConfig) Validate() error {
+	if c.Endpoint == "" {
+		return &s3common.ConfigError{Field: "endpoint", Reason: "is required"}
+	}
+
+	if c.KeyPairID == "" {
+		return &s3common.ConfigError{Field: "key_pair_id", Reason: "is required"}
+	}
+
+	if (c.PrivateKey == "") == (c.PrivateKeyFile == "") {
+		return &s3common.ConfigError{Field: "private_key", Reason: "or private_key_file is required, but not both"}
+	}
+
+	if c.IPRange != "" {
+		if _, _, err := net.ParseCIDR(c.IPRange); err != nil {
+			return &s3common.ConfigError{Field: "ip_range", Reason: "is invalid: " + err.Error()}
+		}
+	}
+
+	return nil
+}
+
+// CloudFrontPolicy CloudFront 自定义策略
+// 参考: https://docs.aws.amazon.com/AmazonCloudFront/latest/DeveloperGuide/private-content-creating-signed-url-custom-policy.html
+type CloudFrontPolicy struct {
+	// required, url of objects, "*" matches any characters, e.g. https://cdn.example.com/videos/123/*
+	Resource string
+
+	// required, access is denied after it
+	ExpireAt time.Time
+
+	// optional, access is denied before it
+	StartAt time.Time
+
+	// optional, CIDR of viewer ip, e.g. 192.0.2.0/24
+	IPRange string
+}
+
+type CloudFrontCookieParams struct {
+	// required, directory of objects, e.g. videos/123/ for HLS playlist and segments
+	RemotePrefix string
+
+	// required, cookie and policy expiration
+	ExpireIn time.Duration
+
+	// optional, CIDR of viewer ip, default to GeneratorCloudFrontConfig.IPRange
+	IPRange string
+
+	// optional, cookie domain, e.g. example.com to share cookies with subdomains, default to host only
+	Domain string
+}
+
+// GeneratorCloudFront 使用 CloudFront 签名 URL 及签名 Cookie 访问私有内容
+//
+// GenerateDownload 默认使用标准策略，配置 IPRange 时使用自定义策略；
+// SignURL 及 GenerateCookies 支持通配符资源，用于 HLS 等需要访问目录下多个文件的场景
+type GeneratorCloudFront struct {
+	endpoint *url.URL
+	cfg      *GeneratorCloudFrontConfig
+	key      *rsa.PrivateKey
+}
+
+func NewGeneratorCloudFront(cfg *GeneratorCloudFrontConfig) (*GeneratorCloudFront, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, &s3common.ConfigError{Field: "endpoint", Reason: "is invalid: " + err.Error()}
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, &s3common.ConfigError{Field: "endpoint", Reason: "scheme must be http or https"}
+	}
+
+	pemData := []byte(cfg.PrivateKey)
+	if cfg.PrivateKeyFile != "" {
+		pemData, err = os.ReadFile(cfg.PrivateKeyFile)
+		if err != nil {
+			return nil, &s3common.ConfigError{Field: "private_key_file", Reason: "is unreadable: " + err.Error()}
+		}
+	}
+
+	key, err := parseRSAPrivateKey(pemData)
+	if err != nil {
+		return nil, &s3common.ConfigError{Field: "private_key", Reason: "is invalid: " + err.Error()}
+	}
+
+	return &GeneratorCloudFront{
+		cfg:      cfg,
+		endpoint: u,
+		key:      key,
+	}, nil
+}
+
+func (d *GeneratorCloudFront) GenerateDownload(_ context.Context, params *GenerateParams) (*url.URL, error) {
+	if params.VersionID != "" {
+		return nil, errVersionIDNotSupported
+	}
+
+	query := make(url.Values)
+
+	if !d.cfg.DisableResponseContentType && params.ContentType != "" {
+		query.Set("response-content-type", params.ContentType)
+	}
+
+	if !d.cfg.DisableResponseContentDisposition && params.AttachmentFilename != "" {
+		query.Set("response-content-disposition", s3common.ComposeContentDisposition(params.AttachmentFilename))
+	}
+
+	u := composeObjectURL(d.endpoint, d.cfg.Prefix, params.RemotePath)
+	u.RawQuery = query.Encode()
+
+	expireAt := timeNow().Add(params.ExpireIn)
+
+	if d.cfg.IPRange == "" {
+		return d.signCanned(u, expireAt)
+	}
+
+	return d.SignURL(u, &CloudFrontPolicy{
+		Resource: u.String(),
+		ExpireAt: expireAt,
+		IPRange:  d.cfg.IPRange,
+	})
+}
+
+// signCanned 使用标准策略签名，策略由 CloudFront 根据 URL 及 Expires 还原，URL 较短
+func (d *GeneratorCloudFront) signCanned(u *url.URL, expireAt time.Time) (*url.URL, error) {
+	policy, err := d.marshalPolicy(&CloudFrontPolicy{Resource: u.String(), ExpireAt: expireAt})
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := d.sign(policy)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("Expires", strconv.FormatInt(expireAt.Unix(), 10))
+	query.Set("Signature", signature)
+	query.Set("Key-Pair-Id", d.cfg.KeyPairID)
+
+	ret := *u
+	ret.RawQuery = appendRawQuery(u.RawQuery, query, "Expires", "Signature", "Key-Pair-Id")
+	return &ret, nil
+}
+
+// SignURL 使用自定义策略签名 u，policy.Resource 可包含通配符，
+// 例如使用 videos/123/* 签名 HLS 播放列表后，可将相同的签名参数附加到同目录的分片 URL
+func (d *GeneratorCloudFront) SignURL(u *url.URL, policy *CloudFrontPolicy) (*url.URL, error) {
+	policyJSON, err := d.marshalPolicy(policy)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := d.sign(policyJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("Policy", cloudFrontBase64(policyJSON))
+	query.Set("Signature", signature)
+	query.Set("Key-Pair-Id", d.cfg.KeyPairID)
+
+	ret := *u
+	ret.RawQuery = appendRawQuery(u.RawQuery, query, "Policy", "Signature", "Key-Pair-Id")
+	return &ret, nil
+}
+
+// GenerateCookies 生成访问目录下全部文件的签名 Cookie，适用于 HLS 等无法为每个分片签名 URL 的场景
+//
+// Cookie 需要由与 Endpoint 同域的服务设置，且 Endpoint 需使用备用域名
+func (d *GeneratorCloudFront) GenerateCookies(params *CloudFrontCookieParams) ([]*http.Cookie, error) {
+	if params.ExpireIn <= 0 {
+		return nil, fmt.Errorf("%w: expire in is required", s3common.ErrInvalidArgument)
+	}
+
+	dir := composeObjectURL(d.endpoint, d.cfg.Prefix, params.RemotePrefix)
+	dir.RawQuery = ""
+	resource := strings.TrimSuffix(dir.String(), "/") + "/*" // "*" would be escaped by url.URL
+
+	expireAt := timeNow().Add(params.ExpireIn)
+
+	ipRange := params.IPRange
+	if ipRange == "" {
+		ipRange = d.cfg.IPRange
+	}
+
+	policy, err := d.marshalPolicy(&CloudFrontPolicy{
+		Resource: resource,
+		ExpireAt: expireAt,
+		IPRange:  ipRange,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := d.sign(policy)
+	if err != nil {
+		return nil, err
+	}
+
+	values := [][2]string{
+		{"CloudFront-Policy", cloudFrontBase64(policy)},
+		{"CloudFront-Signature", signature},
+		{"CloudFront-Key-Pair-Id", d.cfg.KeyPairID},
+	}
+
+	cookies := make([]*http.Cookie, 0, len(values))
+	for _, v := range values {
+		cookies = append(cookies, &http.Cookie{
+			Name:     v[0],
+			Value:    v[1],
+			Path:     path.Clean("/" + dir.Path),
+			Domain:   params.Domain,
+			Expires:  expireAt,
+			Secure:   d.endpoint.Scheme == "https",
+			HttpOnly: true,
+		})
+	}
+	return cookies, nil
+}
+
+type cloudFrontPolicy struct {
+	Statement []cloudFrontStatement `json:"Statement"`
+}
+
+type cloudFrontStatement struct {
+	Resource  string              `json:"Resource"`
+	Condition cloudFrontCondition `json:"Condition"`
+}
+
+type cloudFrontCondition struct {
+	DateLessThan    cloudFrontEpochTime  `json:"DateLessThan"`
+	DateGreaterThan *cloudFrontEpochTime `json:"DateGreaterThan,omitempty"`
+	IPAddress       *cloudFrontSourceIP  `json:"IpAddress,omitempty"`
+}
+
+type cloudFrontEpochTime struct {
+	EpochTime int64 `json:"AWS:EpochTime"`
+}
+
+type cloudFrontSourceIP struct {
+	SourceIP string `json:"AWS:SourceIp"`
+}
+
+// marshalPolicy 生成无空白的策略 JSON，标准策略需要与 CloudFront 还原的内容逐字节一致
+func (d *GeneratorCloudFront) marshalPolicy(p *CloudFrontPolicy) ([]byte, error) {
+	if p.Resource == "" || p.ExpireAt.IsZero() {
+		return nil, fmt.Errorf("%w: resource and expire at are required", s3common.ErrInvalidArgument)
+	}
+
+	cond := cloudFrontCondition{DateLessThan: cloudFrontEpochTime{EpochTime: p.ExpireAt.Unix()}}
+	if !p.StartAt.IsZero() {
+		cond.DateGreaterThan = &cloudFrontEpochTime{EpochTime: p.StartAt.Unix()}
+	}
+	if p.IPRange != "" {
+		if _, _, err := net.ParseCIDR(p.IPRange); err != nil {
+			return nil, fmt.Errorf("%w: invalid ip range: %w", s3common.ErrInvalidArgument, err)
+		}
+		cond.IPAddress = &cloudFrontSourceIP{SourceIP: p.IPRange}
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false) // keep "&" in resource url as is
+	if err := enc.Encode(cloudFrontPolicy{Statement: []cloudFrontStatement{{Resource: p.Resource, Condition: cond}}}); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// sign 使用 RSA-SHA1 签名策略
+func (d *GeneratorCloudFront) sign(policy []byte) (string, error) {
+	digest := sha1.Sum(policy)
+	signature, err := rsa.SignPKCS1v15(rand.Reader, d.key, crypto.SHA1, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return cloudFrontBase64(signature), nil
+}
+
+// cloudFrontBase64 CloudFront 使用的 URL 安全 base64，替换 "+"、"="、"/" 为 "-"、"_"、"~"
+func cloudFrontBase64(data []byte) string {
+	return strings.NewReplacer("+", "-", "=", "_", "/", "~").Replace(base64.StdEncoding.EncodeToString(data))
+}
+
+// appendRawQuery 将签名参数按顺序追加到原有 query 之后，原有参数保持签名时的编码
+func appendRawQuery(rawQuery string, query url.Values, keys ...string) string {
+	parts := make([]string, 0, len(keys)+1)
+	if rawQuery != "" {
+		parts = append(parts, rawQuery)
+	}
+	for _, k := range keys {
+		parts = append(parts, url.QueryEscape(k)+"="+url.QueryEscape(query.Get(k)))
+	}
+	return strings.Join(parts, "&")
+}
+
+func parseRSAPrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no pem block found")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not rsa")
+	}
+	return rsaKey, nil
+}
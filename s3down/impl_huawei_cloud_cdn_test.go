@@ -0,0 +1,59 @@
+package s3down_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ix64/s3-go/s3down"
+)
+
+// TestGeneratorHuaweiCloudCDN 固定时间及随机数，校验固定的签名链接
+//
+// 期望值按 "鉴权方式A/B/C1/C2" 的签名串格式，以 HMAC-SHA256(AuthKey, 签名串) 独立计算
+func TestGeneratorHuaweiCloudCDN(t *testing.T) {
+	s3down.SetClock(t, time.Unix(1700000000, 0), "0123456789abcdef0123456789abcdef")
+
+	for _, v := range []struct {
+		mode s3down.HuaweiCloudCDNAuthMode
+		want string
+	}{
+		{
+			// sign text: /app/dir/a%20b.txt-1700000000-0123456789abcdef0123456789abcdef-0-secret
+			mode: s3down.HuaweiCloudCDNAuthModeA,
+			want: "https://cdn.example.com/app/dir/a%20b.txt?auth_key=1700000000-0123456789abcdef0123456789abcdef-0-8213f43a09933b76645de27df8618885a4ac9f432b2078086bcf45ac27315e7c",
+		},
+		{
+			// sign text: secret202311150613/app/dir/a%20b.txt
+			mode: s3down.HuaweiCloudCDNAuthModeB,
+			want: "https://cdn.example.com/202311150613/cccd3ce090d9aae5c375bf3a1a3c9f910fad9ff2fe3684cf1ed549846477e3f8/app/dir/a%20b.txt",
+		},
+		{
+			// sign text: secret/app/dir/a%20b.txt6553f100
+			mode: s3down.HuaweiCloudCDNAuthModeC1,
+			want: "https://cdn.example.com/2b323bce8e96da8529034812d68f2d0b4d37adce3ce1eb080dd3145e313ab4cc/6553f100/app/dir/a%20b.txt",
+		},
+		{
+			// sign text: secret/app/dir/a%20b.txt6553f100
+			mode: s3down.HuaweiCloudCDNAuthModeC2,
+			want: "https://cdn.example.com/app/dir/a%20b.txt?auth_key=2b323bce8e96da8529034812d68f2d0b4d37adce3ce1eb080dd3145e313ab4cc&timestamp=6553f100",
+		},
+	} {
+		t.Run(string(v.mode), func(t *testing.T) {
+			g, err := s3down.NewGeneratorHuaweiCloudCDN(&s3down.GeneratorHuaweiCloudCDNConfig{
+				GeneratorConfigCommon: s3down.GeneratorConfigCommon{Prefix: "app"},
+				Endpoint:              "https://cdn.example.com",
+				AuthMode:              v.mode,
+				AuthKey:               "secret",
+			})
+			require.NoError(t, err)
+
+			u, err := g.GenerateDownload(context.Background(), &s3down.GenerateParams{RemotePath: "dir/a b.txt", ExpireIn: time.Hour})
+			require.NoError(t, err)
+			assert.Equal(t, v.want, u.String())
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package s3down
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/minio/minio-go/v7/pkg/credentials"
+
+	"github.com/ix64/s3-go/s3common"
+)
+
+// 七牛不支持通过 query 覆盖 Content-Type，下载文件名使用 attname 参数指定
+// 参考: https://developer.qiniu.com/kodo/1659/download-setting
+const qiniuQueryAttname = "attname"
+
+type GeneratorQiniuCDNConfig struct {
+	GeneratorConfigCommon
+
+	// Endpoint 填写CDN URL，例如：https://cdn.example.com
+	Endpoint string `json:"endpoint"`
+
+	// AuthKey 填写控制台 “时间戳防盗链” 里的 “主KEY” 或 “副KEY”
+	AuthKey string `json:"auth_key"`
+}
+
+func (c *GeneratorQiniuCDNConfig) Validate() error {
+	if c.Endpoint == "" {
+		return &s3common.ConfigError{Field: "endpoint", Reason: "is required"}
+	}
+
+	if c.AuthKey == "" {
+		return &s3common.ConfigError{Field: "auth_key", Reason: "is required"}
+	}
+
+	return nil
+}
+
+// GeneratorQiniuCDN 七牛CDN 时间戳防盗链，URL 参数为 sign=md5(key+path+t)&t=hex(expire)
+// 参考: https://developer.qiniu.com/fusion/kb/1670/timestamp-hotlinking-prevention
+type GeneratorQiniuCDN struct {
+	endpoint *url.URL
+	cfg      *GeneratorQiniuCDNConfig
+}
+
+func NewGeneratorQiniuCDN(cfg *GeneratorQiniuCDNConfig) (*GeneratorQiniuCDN, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, &s3common.ConfigError{Field: "endpoint", Reason: "is invalid: " + err.Error()}
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, &s3common.ConfigError{Field: "endpoint", Reason: "scheme must be http or https"}
+	}
+
+	return &GeneratorQiniuCDN{
+		cfg:      cfg,
+		endpoint: u,
+	}, nil
+}
+
+func (d *GeneratorQiniuCDN) GenerateDownload(_ context.Context, params *GenerateParams) (*url.URL, error) {
+	if params.VersionID != "" {
+		return nil, errVersionIDNotSupported
+	}
+
+	u := composeObjectURL(d.endpoint, d.cfg.Prefix, params.RemotePath)
+	u.Path = "/" + strings.TrimPrefix(u.Path, "/") // signed path must be same as request path
+
+	query := qiniuQuery(&d.cfg.GeneratorConfigCommon, params)
+
+	// t 为十六进制的过期时间
+	ts := strconv.FormatInt(timeNow().Add(params.ExpireIn).Unix(), 16)
+
+	signText := strings.Join([]string{d.cfg.AuthKey, u.EscapedPath(), ts}, "")
+	sign := md5.Sum([]byte(signText))
+
+	query.Set("sign", hex.EncodeToString(sign[:]))
+	query.Set("t", ts)
+
+	u.RawQuery = query.Encode()
+	return u, nil
+}
+
+type GeneratorQiniuKodoConfig struct {
+	GeneratorConfigCommon
+
+	// Endpoint 填写空间绑定的下载域名，例如：https://files.example.com
+	Endpoint string `json:"endpoint"`
+
+	AccessKey string `json:"access_key"`
+	SecretKey string `json:"secret_key"`
+
+	// Creds is optional, take precedence over AccessKey and SecretKey, used to share credentials with client.
+	// 七牛下载凭证不支持 SessionToken，因此只能使用长期凭证
+	Creds *credentials.Credentials `json:"-"`
+}
+
+func (c *GeneratorQiniuKodoConfig) Validate() error {
+	if c.Endpoint == "" {
+		return &s3common.ConfigError{Field: "endpoint", Reason: "is required"}
+	}
+
+	if c.Creds == nil && (c.AccessKey == "" || c.SecretKey == "") {
+		return &s3common.ConfigError{Field: "access_key", Reason: "and secret_key is required"}
+	}
+
+	return nil
+}
+
+// GeneratorQiniuKodo 七牛私有空间下载凭证，URL 参数为 e=deadline&token=AccessKey:sign
+// 参考: https://developer.qiniu.com/kodo/1202/download-token
+type GeneratorQiniuKodo struct {
+	endpoint *url.URL
+	cfg      *GeneratorQiniuKodoConfig
+}
+
+func NewGeneratorQiniuKodo(cfg *GeneratorQiniuKodoConfig) (*GeneratorQiniuKodo, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, &s3common.ConfigError{Field: "endpoint", Reason: "is invalid: " + err.Error()}
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, &s3common.ConfigError{Field: "endpoint", Reason: "scheme must be http or https"}
+	}
+
+	return &GeneratorQiniuKodo{
+		cfg:      cfg,
+		endpoint: u,
+	}, nil
+}
+
+func (d *GeneratorQiniuKodo) GenerateDownload(ctx context.Context, params *GenerateParams) (*url.URL, error) {
+	if params.VersionID != "" {
+		return nil, errVersionIDNotSupported
+	}
+
+	accessKey, secretKey, err := s3common.LongTermKeys(ctx, d.cfg.Creds, d.cfg.AccessKey, d.cfg.SecretKey)
+	if err != nil {
+		return nil, err
+	}
+
+	u := composeObjectURL(d.endpoint, d.cfg.Prefix, params.RemotePath)
+	u.Path = "/" + strings.TrimPrefix(u.Path, "/")
+
+	query := qiniuQuery(&d.cfg.GeneratorConfigCommon, params)
+
+	// e 需要位于签名内容中，因此追加到 query 末尾后对完整 URL 签名
+	e := "e=" + strconv.FormatInt(timeNow().Add(params.ExpireIn).Unix(), 10)
+	if raw := query.Encode(); raw != "" {
+		u.RawQuery = raw + "&" + e
+	} else {
+		u.RawQuery = e
+	}
+
+	mac := hmac.New(sha1.New, []byte(secretKey))
+	mac.Write([]byte(u.String()))
+	token := accessKey + ":" + base64.URLEncoding.EncodeToString(mac.Sum(nil))
+
+	u.RawQuery += "&token=" + url.QueryEscape(token)
+	return u, nil
+}
+
+func qiniuQuery(cfg *GeneratorConfigCommon, params *GenerateParams) url.Values {
+	query := make(url.Values)
+	if !cfg.DisableResponseContentDisposition && params.AttachmentFilename != "" {
+		query.Set(qiniuQueryAttname, params.AttachmentFilename)
+	}
+	return query
+}
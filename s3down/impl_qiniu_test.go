@@ -0,0 +1,55 @@
+package s3down_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ix64/s3-go/s3down"
+)
+
+// TestGeneratorQiniuCDN 固定时间，校验固定的签名链接
+//
+// 期望值按时间戳防盗链的签名串格式，以 md5 独立计算
+func TestGeneratorQiniuCDN(t *testing.T) {
+	s3down.SetClock(t, time.Unix(1700000000, 0), "")
+
+	g, err := s3down.NewGeneratorQiniuCDN(&s3down.GeneratorQiniuCDNConfig{
+		Endpoint: "https://cdn.example.com",
+		AuthKey:  "secret",
+	})
+	require.NoError(t, err)
+
+	// sign text: secret/dir/a%20b.txt6553ff10
+	u, err := g.GenerateDownload(context.Background(), &s3down.GenerateParams{
+		RemotePath:         "dir/a b.txt",
+		ExpireIn:           time.Hour,
+		AttachmentFilename: "a.txt",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "https://cdn.example.com/dir/a%20b.txt?attname=a.txt&sign=00c1de125065ac432c871de288aa0bf7&t=6553ff10", u.String())
+}
+
+// TestGeneratorQiniuKodo 使用下载凭证文档示例中的链接及密钥，期望值以 HMAC-SHA1 独立计算
+// 参考: https://developer.qiniu.com/kodo/1202/download-token
+func TestGeneratorQiniuKodo(t *testing.T) {
+	s3down.SetClock(t, time.Unix(1451491200, 0).Add(-time.Hour), "")
+
+	g, err := s3down.NewGeneratorQiniuKodo(&s3down.GeneratorQiniuKodoConfig{
+		Endpoint:  "http://78re52.com1.z0.glb.clouddn.com",
+		AccessKey: "MY_ACCESS_KEY",
+		SecretKey: "MY_SECRET_KEY",
+	})
+	require.NoError(t, err)
+
+	// sign text: http://78re52.com1.z0.glb.clouddn.com/resource/flower.jpg?e=1451491200
+	u, err := g.GenerateDownload(context.Background(), &s3down.GenerateParams{
+		RemotePath: "resource/flower.jpg",
+		ExpireIn:   time.Hour,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "http://78re52.com1.z0.glb.clouddn.com/resource/flower.jpg?e=1451491200&token=MY_ACCESS_KEY%3A438dd8pXocjYuF-6dTcKMtETB2g%3D", u.String())
+}
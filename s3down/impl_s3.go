@@ -2,12 +2,12 @@ package s3down
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"path"
 
+	"github.com/minio/minio-go/v7/pkg/credentials"
 	"github.com/minio/minio-go/v7/pkg/s3utils"
 	"github.com/minio/minio-go/v7/pkg/signer"
 
@@ -26,27 +26,39 @@ type GeneratorS3Config struct {
 	PublicRead bool   `json:"public_read"`
 	AccessKey  string `json:"access_key"`
 	SecretKey  string `json:"secret_key"`
+
+	// Credentials is optional, take precedence over AccessKey and SecretKey
+	Credentials *s3common.CredentialsConfig `json:"credentials"`
+
+	// Creds is optional, take precedence over Credentials, used to share refreshing credentials
+	Creds *credentials.Credentials `json:"-"`
 }
 
 func (c *GeneratorS3Config) Validate() error {
 	if c.Endpoint == "" {
-		return errors.New("endpoint is required")
+		return &s3common.ConfigError{Field: "endpoint", Reason: "is required"}
 	}
 
 	if c.Bucket == "" {
-		return errors.New("bucket is required")
+		return &s3common.ConfigError{Field: "bucket", Reason: "is required"}
 	}
 
 	if c.Region == "" {
-		return errors.New("region is required")
+		return &s3common.ConfigError{Field: "region", Reason: "is required"}
 	}
 
-	if !c.PublicRead && (c.AccessKey == "" || c.SecretKey == "") {
-		return errors.New("access key and secret key is required when anonymous is false")
+	if !c.PublicRead && c.Creds == nil && c.Credentials == nil && (c.AccessKey == "" || c.SecretKey == "") {
+		return &s3common.ConfigError{Field: "access_key", Reason: "and secret_key is required when public_read is false"}
+	}
+
+	if c.Credentials != nil {
+		if err := c.Credentials.Validate(); err != nil {
+			return err
+		}
 	}
 
 	if c.BucketLookup == "" {
-		return errors.New("bucket lookup is required")
+		return &s3common.ConfigError{Field: "bucket_lookup", Reason: "is required"}
 	}
 
 	return nil
@@ -59,11 +71,11 @@ func NewGeneratorS3(cfg *GeneratorS3Config) (*GeneratorS3, error) {
 
 	u, err := url.Parse(cfg.Endpoint)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse endpoint: %w", err)
+		return nil, &s3common.ConfigError{Field: "endpoint", Reason: "is invalid: " + err.Error()}
 	}
 
 	if u.Scheme != "http" && u.Scheme != "https" {
-		return nil, errors.New("endpoint scheme must be http or https")
+		return nil, &s3common.ConfigError{Field: "endpoint", Reason: "scheme must be http or https"}
 	}
 
 	switch cfg.BucketLookup {
@@ -74,12 +86,21 @@ func NewGeneratorS3(cfg *GeneratorS3Config) (*GeneratorS3, error) {
 	case s3common.BucketLookupCNAME:
 		// do nothing
 	default:
-		return nil, fmt.Errorf("unknown bucket lookup type: %s", cfg.BucketLookup)
+		return nil, &s3common.ConfigError{Field: "bucket_lookup", Reason: "is unknown: " + string(cfg.BucketLookup)}
+	}
+
+	var creds *credentials.Credentials
+	if !cfg.PublicRead {
+		creds, err = s3common.ResolveCredentials(cfg.Creds, cfg.Credentials, cfg.AccessKey, cfg.SecretKey)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return &GeneratorS3{
 		endpoint: u,
 		cfg:      cfg,
+		creds:    creds,
 	}, nil
 }
 
@@ -87,9 +108,10 @@ func NewGeneratorS3(cfg *GeneratorS3Config) (*GeneratorS3, error) {
 type GeneratorS3 struct {
 	cfg      *GeneratorS3Config
 	endpoint *url.URL
+	creds    *credentials.Credentials
 }
 
-func (d *GeneratorS3) GenerateDownload(_ context.Context, params *GenerateParams) (*url.URL, error) {
+func (d *GeneratorS3) GenerateDownload(ctx context.Context, params *GenerateParams) (*url.URL, error) {
 	reqParams := make(url.Values)
 
 	if !d.cfg.DisableResponseContentType && params.ContentType != "" {
@@ -100,16 +122,26 @@ func (d *GeneratorS3) GenerateDownload(_ context.Context, params *GenerateParams
 		reqParams.Set("response-content-disposition", s3common.ComposeContentDisposition(params.AttachmentFilename))
 	}
 
+	if params.VersionID != "" {
+		reqParams.Set("versionId", params.VersionID)
+	}
+
 	ret := composeObjectURL(d.endpoint, d.cfg.Prefix, params.RemotePath)
 	ret.RawPath = s3utils.EncodePath(ret.Path)
 	ret.RawQuery = reqParams.Encode()
 
 	if !d.cfg.PublicRead {
-		// this request never send, skip context and error
-		req, _ := http.NewRequest(http.MethodGet, ret.String(), nil)
+		// temporary credentials are refreshed before expiry
+		v, err := d.creds.GetWithContext(s3common.NewCredContext(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to get credentials: %w", s3common.ErrInvalidCredentials, err)
+		}
+
+		// this request never send, skip error
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, ret.String(), nil)
 
 		expireIn := int64(params.ExpireIn.Seconds())
-		req = signer.PreSignV4(*req, d.cfg.AccessKey, d.cfg.SecretKey, "", d.cfg.Region, expireIn)
+		req = signer.PreSignV4(*req, v.AccessKeyID, v.SecretAccessKey, v.SessionToken, d.cfg.Region, expireIn)
 
 		ret = req.URL
 	}
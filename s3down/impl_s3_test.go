@@ -0,0 +1,64 @@
+package s3down_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ix64/s3-go/s3common"
+	"github.com/ix64/s3-go/s3down"
+)
+
+func TestGeneratorS3SessionToken(t *testing.T) {
+	g, err := s3down.NewGeneratorS3(&s3down.GeneratorS3Config{
+		Endpoint:     "https://s3.example.com",
+		Bucket:       "my-bucket",
+		BucketLookup: s3common.BucketLookupPath,
+		Region:       "us-east-1",
+		Credentials: &s3common.CredentialsConfig{
+			Type:         s3common.CredentialsTypeStatic,
+			AccessKey:    "ak",
+			SecretKey:    "sk",
+			SessionToken: "token",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := g.GenerateDownload(context.Background(), &s3down.GenerateParams{
+		RemotePath: "hello.txt",
+		ExpireIn:   time.Minute,
+	})
+	assert.NoError(t, err)
+
+	query := u.Query()
+	assert.Equal(t, "token", query.Get("X-Amz-Security-Token"))
+	assert.Contains(t, query.Get("X-Amz-Credential"), "ak/")
+	assert.NotEmpty(t, query.Get("X-Amz-Signature"))
+}
+
+func TestGeneratorS3VersionID(t *testing.T) {
+	g, err := s3down.NewGeneratorS3(&s3down.GeneratorS3Config{
+		Endpoint:     "https://s3.example.com",
+		Bucket:       "my-bucket",
+		BucketLookup: s3common.BucketLookupPath,
+		Region:       "us-east-1",
+		AccessKey:    "ak",
+		SecretKey:    "sk",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := g.GenerateDownload(context.Background(), &s3down.GenerateParams{
+		RemotePath: "hello.txt",
+		ExpireIn:   time.Minute,
+		VersionID:  "v1",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "v1", u.Query().Get("versionId"))
+	assert.NotEmpty(t, u.Query().Get("X-Amz-Signature"))
+}
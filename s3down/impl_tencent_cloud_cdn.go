@@ -4,7 +4,6 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
-	"errors"
 	"fmt"
 	"net/url"
 	"path"
@@ -84,15 +83,15 @@ type GeneratorTencentCloudCDNConfig struct {
 
 func (c *GeneratorTencentCloudCDNConfig) Validate() error {
 	if c.Endpoint == "" {
-		return errors.New("endpoint is required")
+		return &s3common.ConfigError{Field: "endpoint", Reason: "is required"}
 	}
 
 	if !slices.Contains(TencentCloudCDNAuthModes, c.AuthMode) {
-		return fmt.Errorf("unknown auth mode: %s", c.AuthMode)
+		return &s3common.ConfigError{Field: "auth_mode", Reason: "is unknown: " + string(c.AuthMode)}
 	}
 
 	if c.AuthMode != TencentCloudCDNAuthModeNone && c.AuthKey == "" {
-		return errors.New("auth key is required")
+		return &s3common.ConfigError{Field: "auth_key", Reason: "is required"}
 	}
 
 	return nil
@@ -110,15 +109,11 @@ func NewGeneratorTencentCloudCDN(cfg *GeneratorTencentCloudCDNConfig) (*Generato
 
 	u, err := url.Parse(cfg.Endpoint)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse endpoint: %w", err)
+		return nil, &s3common.ConfigError{Field: "endpoint", Reason: "is invalid: " + err.Error()}
 	}
 
 	if u.Scheme != "http" && u.Scheme != "https" {
-		return nil, errors.New("endpoint scheme must be http or https")
-	}
-
-	if cfg.AuthMode != TencentCloudCDNAuthModeNone && cfg.AuthKey == "" {
-		return nil, errors.New("auth key is required")
+		return nil, &s3common.ConfigError{Field: "endpoint", Reason: "scheme must be http or https"}
 	}
 
 	return &GeneratorTencentCloudCDN{
@@ -129,6 +124,10 @@ func NewGeneratorTencentCloudCDN(cfg *GeneratorTencentCloudCDNConfig) (*Generato
 }
 
 func (d *GeneratorTencentCloudCDN) GenerateDownload(_ context.Context, params *GenerateParams) (*url.URL, error) {
+	if params.VersionID != "" {
+		return nil, errVersionIDNotSupported
+	}
+
 	query := make(url.Values)
 
 	if !d.cfg.DisableResponseContentType && params.ContentType != "" {
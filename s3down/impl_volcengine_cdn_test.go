@@ -0,0 +1,115 @@
+package s3down_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ix64/s3-go/s3common"
+	"github.com/ix64/s3-go/s3down"
+)
+
+// TestGeneratorVolcengineCDN 固定时间及随机数，校验固定的签名链接
+//
+// 期望值按 "鉴权方式A/B/C/D" 的签名串格式，以 md5 或 sha256 独立计算
+func TestGeneratorVolcengineCDN(t *testing.T) {
+	const key = "secret"
+
+	s3down.SetClock(t, time.Unix(1700000000, 0), "0123456789abcdef0123456789abcdef")
+
+	for _, v := range []struct {
+		name string
+		cfg  *s3down.GeneratorVolcengineCDNConfig
+		want string
+	}{
+		{
+			// sign text: /app/dir/a%20b.txt-1700000000-0123456789abcdef0123456789abcdef-0-secret
+			name: "type-a md5",
+			cfg:  &s3down.GeneratorVolcengineCDNConfig{AuthMode: s3down.VolcengineCDNAuthModeA},
+			want: "https://cdn.example.com/app/dir/a%20b.txt?auth_key=1700000000-0123456789abcdef0123456789abcdef-0-e871de721e2721439542ad6de5443420",
+		},
+		{
+			// sign text: secret202311150613/app/dir/a%20b.txt
+			name: "type-b md5",
+			cfg:  &s3down.GeneratorVolcengineCDNConfig{AuthMode: s3down.VolcengineCDNAuthModeB},
+			want: "https://cdn.example.com/202311150613/26d3ac5e8db8553f5184f2e6b2e55ed9/app/dir/a%20b.txt",
+		},
+		{
+			// sign text: secret/app/dir/a%20b.txt6553f100
+			name: "type-c sha256",
+			cfg: &s3down.GeneratorVolcengineCDNConfig{
+				AuthMode:  s3down.VolcengineCDNAuthModeC,
+				Algorithm: s3down.VolcengineCDNAlgorithmSHA256,
+			},
+			want: "https://cdn.example.com/6a1a9fcb5aefdd9c14e200c05492e81216a235cff837b3b6520274a791114361/6553f100/app/dir/a%20b.txt",
+		},
+		{
+			// sign text: secret/app/dir/a%20b.txt6553f100
+			name: "type-d md5",
+			cfg:  &s3down.GeneratorVolcengineCDNConfig{AuthMode: s3down.VolcengineCDNAuthModeD},
+			want: "https://cdn.example.com/app/dir/a%20b.txt?sign=227f48457750b58a4c4ceb198c31704d&t=6553f100",
+		},
+		{
+			// sign text: secret/app/dir/a%20b.txt1700003600
+			name: "type-d custom params",
+			cfg: &s3down.GeneratorVolcengineCDNConfig{
+				AuthMode:        s3down.VolcengineCDNAuthModeD,
+				Algorithm:       s3down.VolcengineCDNAlgorithmSHA256,
+				SignParam:       "auth",
+				TimestampParam:  "ts",
+				TimestampFormat: s3down.VolcengineCDNTimestampDecimal,
+				DynamicExpire:   true,
+			},
+			want: "https://cdn.example.com/app/dir/a%20b.txt?auth=23dfff28be9e21ea298e70e512a8d1a506995fa27fda3ded9b25da39050f917e&ts=1700003600",
+		},
+	} {
+		t.Run(v.name, func(t *testing.T) {
+			v.cfg.GeneratorConfigCommon = s3down.GeneratorConfigCommon{Prefix: "app"}
+			v.cfg.Endpoint = "https://cdn.example.com"
+			v.cfg.AuthKey = key
+
+			g, err := s3down.NewGeneratorVolcengineCDN(v.cfg)
+			require.NoError(t, err)
+
+			u, err := g.GenerateDownload(context.Background(), &s3down.GenerateParams{RemotePath: "dir/a b.txt", ExpireIn: time.Hour})
+			require.NoError(t, err)
+			assert.Equal(t, v.want, u.String())
+		})
+	}
+
+	t.Run("param collision", func(t *testing.T) {
+		for _, cfg := range []*s3down.GeneratorVolcengineCDNConfig{
+			{SignParam: "t"},
+			{TimestampParam: "sign"},
+			{SignParam: "ts", TimestampParam: "ts"},
+		} {
+			cfg.Endpoint = "https://cdn.example.com"
+			cfg.AuthMode = s3down.VolcengineCDNAuthModeD
+			cfg.AuthKey = key
+			_, err := s3down.NewGeneratorVolcengineCDN(cfg)
+			assert.ErrorIs(t, err, s3common.ErrInvalidConfig, "%+v", cfg)
+		}
+
+		// timestamp param is not used by type-a
+		_, err := s3down.NewGeneratorVolcengineCDN(&s3down.GeneratorVolcengineCDNConfig{
+			Endpoint:  "https://cdn.example.com",
+			AuthMode:  s3down.VolcengineCDNAuthModeA,
+			AuthKey:   key,
+			SignParam: "t",
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("unknown algorithm", func(t *testing.T) {
+		_, err := s3down.NewGeneratorVolcengineCDN(&s3down.GeneratorVolcengineCDNConfig{
+			Endpoint:  "https://cdn.example.com",
+			AuthMode:  s3down.VolcengineCDNAuthModeA,
+			AuthKey:   key,
+			Algorithm: "sha1",
+		})
+		assert.Error(t, err)
+	})
+}
@@ -15,6 +15,9 @@ type GenerateParams struct {
 
 	// optional, expect to response with "Content-Type" header
 	ContentType string
+
+	// optional, pin a specific object version, supported by s3 generator only
+	VersionID string
 }
 
 // Generator 为终端用户生成预签名的下载链接，一般由对象存储或CDN服务提供
@@ -0,0 +1,405 @@
+package s3fs
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/minio/minio-go/v7"
+
+	"github.com/ix64/s3-go/internal/objutil"
+	"github.com/ix64/s3-go/s3"
+	"github.com/ix64/s3-go/s3common"
+	"github.com/ix64/s3-go/s3down"
+	"github.com/ix64/s3-go/s3up"
+)
+
+const (
+	// internalDir 存放元数据及临时文件的目录，位于 Config.Root 下，不能作为 object name 使用
+	internalDir = ".s3fs"
+
+	// metaDir 元数据文件的目录结构与对象相同，内容为 JSON
+	metaDir = internalDir + "/meta"
+
+	// tmpDir 写入中的临时文件，与对象位于同一文件系统，以便原子重命名
+	tmpDir = internalDir + "/tmp"
+
+	defaultContentType = "application/octet-stream"
+)
+
+type Config struct {
+	// required, local directory to store objects
+	Root string `json:"root"`
+
+	// optional, same as s3.Config.Prefix
+	Prefix string `json:"prefix"`
+}
+
+func (c *Config) Validate() error {
+	if c.Root == "" {
+		return &s3common.ConfigError{Field: "root", Reason: "is required"}
+	}
+	return nil
+}
+
+// Client 基于本地目录的 s3.Storage 实现，用于开发环境及单机部署
+//
+// 对象保存为 Config.Root 下的同名文件，Content-Type 及用户元数据保存在 ".s3fs/meta" 下的同名 JSON 文件中，
+// 写入时先写临时文件再重命名，读取方不会看到写了一半的内容
+type Client struct {
+	cfg  *Config
+	root string
+
+	// mu 保证对象文件与元数据文件的重命名对读取方整体可见
+	mu sync.RWMutex
+
+	upload   s3up.Generator
+	download s3down.Generator
+}
+
+var _ s3.Storage = (*Client)(nil)
+
+// metadata 对象的元数据，Size 与文件大小不一致时视为文件被外部修改，元数据失效
+type metadata struct {
+	ContentType    string            `json:"content_type"`
+	ETag           string            `json:"etag"`
+	Size           int64             `json:"size"`
+	ChecksumSHA256 string            `json:"checksum_sha256"`
+	UserMetadata   map[string]string `json:"user_metadata,omitempty"`
+}
+
+// NewClient 使用本地目录作为对象存储，目录不存在时自动创建
+func NewClient(cfg *Config) (*Client, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("failed to validate config: %w", err)
+	}
+
+	root, err := filepath.Abs(cfg.Root)
+	if err != nil {
+		return nil, &s3common.ConfigError{Field: "root", Reason: "is invalid: " + err.Error()}
+	}
+
+	for _, dir := range []string{metaDir, tmpDir} {
+		if err := os.MkdirAll(filepath.Join(root, filepath.FromSlash(dir)), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+		}
+	}
+
+	return &Client{
+		cfg:  cfg,
+		root: root,
+	}, nil
+}
+
+// SetDownloadGenerator 设置下载链接生成器，一般为 NewGenerator 创建的 Generator，配合 NewHandler 使用
+func (c *Client) SetDownloadGenerator(g s3down.Generator) {
+	c.download = g
+}
+
+func (c *Client) SetUploadGenerator(g s3up.Generator) {
+	c.upload = g
+}
+
+func (c *Client) composeObjectName(remotePath string) string {
+	return objutil.ComposeObjectName(c.cfg.Prefix, remotePath)
+}
+
+func (c *Client) composeListPrefix(prefix string) string {
+	return objutil.ComposeListPrefix(c.cfg.Prefix, prefix)
+}
+
+func (c *Client) trimObjectName(objectName string) string {
+	return objutil.TrimObjectName(c.cfg.Prefix, objectName)
+}
+
+// validateObjectName 拒绝逃逸出 Config.Root 或占用内部目录的 object name
+func validateObjectName(objectName string) error {
+	if objectName == "" || objectName == "." ||
+		objectName == ".." || strings.HasPrefix(objectName, "../") ||
+		objectName == internalDir || strings.HasPrefix(objectName, internalDir+"/") {
+		return fmt.Errorf("%w: invalid object name: %q", s3common.ErrInvalidArgument, objectName)
+	}
+	return nil
+}
+
+func (c *Client) dataPath(objectName string) string {
+	return filepath.Join(c.root, filepath.FromSlash(objectName))
+}
+
+func (c *Client) metaPath(objectName string) string {
+	return filepath.Join(c.root, filepath.FromSlash(metaDir), filepath.FromSlash(objectName))
+}
+
+// openObject 打开对象文件并读取元数据，调用方负责关闭文件
+func (c *Client) openObject(objectName string) (*os.File, minio.ObjectInfo, error) {
+	if err := validateObjectName(objectName); err != nil {
+		return nil, minio.ObjectInfo{}, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	f, err := os.Open(c.dataPath(objectName))
+	if err != nil {
+		return nil, minio.ObjectInfo{}, convertPathError(err, objectName)
+	}
+
+	stat, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, minio.ObjectInfo{}, err
+	}
+
+	// directories are implied by object names, they are not objects
+	if stat.IsDir() {
+		_ = f.Close()
+		return nil, minio.ObjectInfo{}, objutil.NotFound(objectName)
+	}
+
+	return f, c.objectInfo(objectName, stat), nil
+}
+
+// statObject 获取对象信息，调用方需持有读锁
+func (c *Client) statObject(objectName string) (minio.ObjectInfo, error) {
+	stat, err := os.Stat(c.dataPath(objectName))
+	if err != nil {
+		return minio.ObjectInfo{}, convertPathError(err, objectName)
+	}
+	if stat.IsDir() {
+		return minio.ObjectInfo{}, objutil.NotFound(objectName)
+	}
+	return c.objectInfo(objectName, stat), nil
+}
+
+// objectInfo 组装对象信息，元数据缺失或失效时根据文件推断
+func (c *Client) objectInfo(objectName string, stat fs.FileInfo) minio.ObjectInfo {
+	meta := c.readMetadata(objectName)
+	if meta == nil || meta.Size != stat.Size() {
+		meta = &metadata{
+			ContentType: mime.TypeByExtension(path.Ext(objectName)),
+
+			// not the MD5 of content, similar to ETag of multipart upload
+			ETag: strconv.FormatInt(stat.ModTime().UnixNano(), 16) + "-" + strconv.FormatInt(stat.Size(), 16),
+			Size: stat.Size(),
+		}
+		if meta.ContentType == "" {
+			meta.ContentType = defaultContentType
+		}
+	}
+
+	modTime := stat.ModTime().UTC()
+
+	header := http.Header{}
+	header.Set("Content-Type", meta.ContentType)
+	header.Set("Content-Length", strconv.FormatInt(stat.Size(), 10))
+	header.Set("Last-Modified", modTime.Format(http.TimeFormat))
+	header.Set("ETag", `"`+meta.ETag+`"`)
+
+	userMetadata := make(minio.StringMap, len(meta.UserMetadata))
+	for k, v := range meta.UserMetadata {
+		header.Set("X-Amz-Meta-"+k, v)
+		userMetadata[k] = v
+	}
+
+	return minio.ObjectInfo{
+		Key:          objectName,
+		ETag:         meta.ETag,
+		Size:         stat.Size(),
+		LastModified: modTime,
+		ContentType:  meta.ContentType,
+		Metadata:     header,
+		UserMetadata: userMetadata,
+
+		ChecksumSHA256: meta.ChecksumSHA256,
+		ChecksumMode:   "FULL_OBJECT",
+	}
+}
+
+func (c *Client) readMetadata(objectName string) *metadata {
+	buf, err := os.ReadFile(c.metaPath(objectName))
+	if err != nil {
+		return nil
+	}
+
+	meta := &metadata{}
+	if err := json.Unmarshal(buf, meta); err != nil {
+		return nil // corrupted metadata, fallback to infer from file
+	}
+	return meta
+}
+
+// writeObject 将 r 的内容写入临时文件，计算 ETag 及校验和后，与元数据一起重命名为目标文件
+//
+// size 为 -1 时读取到 EOF，否则内容长度必须与 size 一致
+func (c *Client) writeObject(objectName string, r io.Reader, size int64, meta *metadata) (err error) {
+	if err := validateObjectName(objectName); err != nil {
+		return err
+	}
+
+	dataTemp, err := c.createTemp()
+	if err != nil {
+		return err
+	}
+	defer removeOnError(dataTemp.Name(), &err)
+	defer dataTemp.Close()
+
+	if size >= 0 {
+		r = io.LimitReader(r, size)
+	}
+
+	md5Hash, sha256Hash := md5.New(), sha256.New()
+	n, err := io.Copy(io.MultiWriter(dataTemp, md5Hash, sha256Hash), r)
+	if err != nil {
+		return fmt.Errorf("failed to write content: %w", err)
+	}
+	if size >= 0 && n != size {
+		return fmt.Errorf("%w: size mismatch: expect %d, got %d", s3common.ErrInvalidArgument, size, n)
+	}
+
+	if err := dataTemp.Sync(); err != nil {
+		return err
+	}
+	if err := dataTemp.Close(); err != nil {
+		return err
+	}
+
+	meta = &metadata{
+		ContentType:    meta.ContentType,
+		ETag:           hex.EncodeToString(md5Hash.Sum(nil)),
+		Size:           n,
+		ChecksumSHA256: base64.StdEncoding.EncodeToString(sha256Hash.Sum(nil)),
+		UserMetadata:   maps.Clone(meta.UserMetadata),
+	}
+	if meta.ContentType == "" {
+		meta.ContentType = defaultContentType
+	}
+
+	buf, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+
+	metaTemp, err := c.createTemp()
+	if err != nil {
+		return err
+	}
+	defer removeOnError(metaTemp.Name(), &err)
+	defer metaTemp.Close()
+
+	if _, err := metaTemp.Write(buf); err != nil {
+		return err
+	}
+	if err := metaTemp.Sync(); err != nil {
+		return err
+	}
+	if err := metaTemp.Close(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.commit(objectName, dataTemp.Name(), metaTemp.Name())
+}
+
+// commit 将临时文件重命名为对象文件及元数据文件，调用方需持有写锁
+func (c *Client) commit(objectName string, dataTemp string, metaTemp string) error {
+	dataPath, metaPath := c.dataPath(objectName), c.metaPath(objectName)
+
+	for _, dir := range []string{filepath.Dir(dataPath), filepath.Dir(metaPath)} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return convertWriteError(err, objectName)
+		}
+	}
+
+	// metadata first, a stale metadata with mismatched size is ignored by readers
+	if err := os.Rename(metaTemp, metaPath); err != nil {
+		return convertWriteError(err, objectName)
+	}
+	if err := os.Rename(dataTemp, dataPath); err != nil {
+		return convertWriteError(err, objectName)
+	}
+	return nil
+}
+
+// removeObject 删除对象文件及元数据文件，并清理空目录，调用方需持有写锁
+func (c *Client) removeObject(objectName string) error {
+	if err := validateObjectName(objectName); err != nil {
+		return err
+	}
+
+	dataPath := c.dataPath(objectName)
+	if stat, err := os.Stat(dataPath); err == nil && stat.IsDir() {
+		return nil // not an object
+	}
+
+	for _, p := range []string{dataPath, c.metaPath(objectName)} {
+		if err := os.Remove(p); err != nil && !isNotExist(err) {
+			return err
+		}
+	}
+
+	c.pruneDirs(objectName)
+	return nil
+}
+
+// pruneDirs 删除 object name 所在的空目录，S3 中目录不独立存在
+func (c *Client) pruneDirs(objectName string) {
+	for _, base := range []string{c.root, filepath.Join(c.root, filepath.FromSlash(metaDir))} {
+		for dir := path.Dir(objectName); dir != "." && dir != "/"; dir = path.Dir(dir) {
+			// fail on non-empty directory
+			if err := os.Remove(filepath.Join(base, filepath.FromSlash(dir))); err != nil {
+				break
+			}
+		}
+	}
+}
+
+func (c *Client) createTemp() (*os.File, error) {
+	return os.CreateTemp(filepath.Join(c.root, filepath.FromSlash(tmpDir)), "object-*")
+}
+
+func removeOnError(name string, err *error) {
+	if *err != nil {
+		_ = os.Remove(name)
+	}
+}
+
+func isNotExist(err error) bool {
+	// "a/b" does not exist if "a" is a file
+	return errors.Is(err, fs.ErrNotExist) || errors.Is(err, syscall.ENOTDIR)
+}
+
+// convertPathError 将读取时的文件系统错误转换为对应的错误分类
+func convertPathError(err error, objectName string) error {
+	if isNotExist(err) {
+		return objutil.NotFound(objectName)
+	}
+	return err
+}
+
+// convertWriteError 将写入时的文件系统错误转换为对应的错误分类
+//
+// 文件系统中 "a" 与 "a/b" 不能同时存在，而 S3 中可以，此时返回 ErrConflict
+func convertWriteError(err error, objectName string) error {
+	if errors.Is(err, syscall.ENOTDIR) || errors.Is(err, syscall.EISDIR) || errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("%w: %s conflicts with existing object: %w", s3common.ErrConflict, objectName, err)
+	}
+	return err
+}
@@ -0,0 +1,171 @@
+package s3fs_test
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ix64/s3-go/s3"
+	"github.com/ix64/s3-go/s3down"
+	"github.com/ix64/s3-go/s3fs"
+)
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+
+	root := t.TempDir()
+
+	c, err := s3fs.NewClient(&s3fs.Config{Root: root, Prefix: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content := "hello, s3fs"
+	err = c.UploadWithMetadata(ctx, "docs/hello.txt", strings.NewReader(content), int64(len(content)), "text/plain", map[string]string{
+		"Owner": "unit-test",
+	})
+	assert.NoError(t, err)
+
+	t.Run("Stat", func(t *testing.T) {
+		info, err := c.Stat(ctx, "docs/hello.txt")
+		assert.NoError(t, err)
+
+		sum := md5.Sum([]byte(content))
+		assert.Equal(t, "app/docs/hello.txt", info.Key)
+		assert.Equal(t, hex.EncodeToString(sum[:]), info.ETag)
+		assert.Equal(t, int64(len(content)), info.Size)
+		assert.Equal(t, "text/plain", info.ContentType)
+		assert.Equal(t, "unit-test", info.UserMetadata["Owner"])
+		assert.NotEmpty(t, info.ChecksumSHA256)
+	})
+
+	t.Run("DownloadRange", func(t *testing.T) {
+		ret, err := c.DownloadWithOptions(ctx, "docs/hello.txt", &s3.DownloadOptions{SuffixLength: 4})
+		assert.NoError(t, err)
+		defer ret.Body.Close()
+
+		buf, err := io.ReadAll(ret.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, "s3fs", string(buf))
+		assert.Equal(t, "bytes 7-10/11", ret.ContentRange)
+	})
+
+	t.Run("CopyMove", func(t *testing.T) {
+		assert.NoError(t, c.Copy(ctx, "docs/hello.txt", "docs/copy.txt"))
+		assert.NoError(t, c.Move(ctx, "docs/copy.txt", "archive/moved.txt"))
+
+		_, err := c.Stat(ctx, "docs/copy.txt")
+		assert.ErrorIs(t, err, s3.ErrNotFound)
+
+		info, err := c.Stat(ctx, "archive/moved.txt")
+		assert.NoError(t, err)
+		assert.Equal(t, "text/plain", info.ContentType)
+		assert.Equal(t, "unit-test", info.UserMetadata["Owner"])
+	})
+
+	t.Run("Conflict", func(t *testing.T) {
+		err := c.Upload(ctx, "docs/hello.txt/child", strings.NewReader(""), 0, "")
+		assert.ErrorIs(t, err, s3.ErrConflict)
+
+		_, err = c.Stat(ctx, "../../escape")
+		assert.ErrorIs(t, err, s3.ErrInvalidArgument)
+	})
+
+	t.Run("List", func(t *testing.T) {
+		ret, err := c.List(ctx, "", nil)
+		assert.NoError(t, err)
+		assert.Empty(t, ret.Objects)
+		assert.Equal(t, []string{"archive/", "docs/"}, ret.Prefixes)
+
+		var keys []string
+		for obj, err := range c.Walk(ctx, "") {
+			assert.NoError(t, err)
+			keys = append(keys, obj.Key)
+		}
+		assert.Equal(t, []string{"archive/moved.txt", "docs/hello.txt"}, keys)
+	})
+
+	t.Run("Handler", func(t *testing.T) {
+		mux := http.NewServeMux()
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		g, err := s3fs.NewGenerator(&s3fs.GeneratorConfig{
+			GeneratorConfigCommon: s3down.GeneratorConfigCommon{Prefix: "app"},
+			Endpoint:              server.URL + "/files",
+			SecretKey:             "secret",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.SetDownloadGenerator(g)
+		mux.Handle("/files/", s3fs.NewHandler(c, g))
+
+		u, err := c.GenerateDownload(ctx, &s3down.GenerateParams{
+			RemotePath:         "docs/hello.txt",
+			ExpireIn:           time.Minute,
+			AttachmentFilename: "hello.txt",
+		})
+		assert.NoError(t, err)
+
+		resp, err := http.Get(u.String())
+		assert.NoError(t, err)
+		buf, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, content, string(buf))
+		assert.Equal(t, "text/plain", resp.Header.Get("Content-Type"))
+		assert.Contains(t, resp.Header.Get("Content-Disposition"), "hello.txt")
+
+		// tamper with the path
+		tampered := *u
+		tampered.Path = "/files/app/archive/moved.txt"
+		resp, err = http.Get(tampered.String())
+		assert.NoError(t, err)
+		_ = resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("PrefixOutOfScope", func(t *testing.T) {
+		other, err := s3fs.NewClient(&s3fs.Config{Root: root, Prefix: "other"})
+		assert.NoError(t, err)
+		assert.NoError(t, other.Upload(ctx, "x", strings.NewReader("x"), 1, "text/plain"))
+
+		for _, prefix := range []string{"..", "../other/", "a/../../other", "/../app-other/"} {
+			failed, err := c.DeletePrefix(ctx, prefix)
+			assert.ErrorIs(t, err, s3.ErrInvalidArgument, prefix)
+			assert.Empty(t, failed)
+
+			_, err = c.List(ctx, prefix, nil)
+			assert.ErrorIs(t, err, s3.ErrInvalidArgument, prefix)
+
+			walked := false
+			for _, err := range c.Walk(ctx, prefix) {
+				walked = true
+				assert.ErrorIs(t, err, s3.ErrInvalidArgument, prefix)
+			}
+			assert.True(t, walked, prefix)
+		}
+
+		_, err = other.Stat(ctx, "x")
+		assert.NoError(t, err)
+	})
+
+	t.Run("DeletePrefix", func(t *testing.T) {
+		failed, err := c.DeletePrefix(ctx, "")
+		assert.NoError(t, err)
+		assert.Empty(t, failed)
+
+		ret, err := c.List(ctx, "", &s3.ListOptions{Recursive: true})
+		assert.NoError(t, err)
+		assert.Empty(t, ret.Objects)
+	})
+}
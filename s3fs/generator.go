@@ -0,0 +1,157 @@
+package s3fs
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ix64/s3-go/s3common"
+	"github.com/ix64/s3-go/s3down"
+)
+
+const (
+	queryExpires   = "expires"
+	querySignature = "signature"
+
+	queryResponseContentType        = "response-content-type"
+	queryResponseContentDisposition = "response-content-disposition"
+)
+
+var (
+	errSignatureExpired  = errors.New("signature expired")
+	errSignatureMismatch = errors.New("signature mismatch")
+)
+
+type GeneratorConfig struct {
+	s3down.GeneratorConfigCommon
+
+	// Endpoint 填写 Handler 对外提供服务的 URL，例如：https://files.example.com/objects
+	Endpoint string `json:"endpoint"`
+
+	// SecretKey HMAC-SHA256 签名密钥，Handler 使用同一个 Generator 校验签名
+	SecretKey string `json:"secret_key"`
+}
+
+func (c *GeneratorConfig) Validate() error {
+	if c.Endpoint == "" {
+		return &s3common.ConfigError{Field: "endpoint", Reason: "is required"}
+	}
+
+	if c.SecretKey == "" {
+		return &s3common.ConfigError{Field: "secret_key", Reason: "is required"}
+	}
+
+	return nil
+}
+
+// Generator 为 Handler 生成 HMAC-SHA256 签名的下载链接
+//
+// 签名内容为 URL Path、过期时间及 response-content-type、response-content-disposition 参数，
+// 因此这两个参数无法被篡改
+type Generator struct {
+	endpoint *url.URL
+	cfg      *GeneratorConfig
+}
+
+var _ s3down.Generator = (*Generator)(nil)
+
+func NewGenerator(cfg *GeneratorConfig) (*Generator, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, &s3common.ConfigError{Field: "endpoint", Reason: "is invalid: " + err.Error()}
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, &s3common.ConfigError{Field: "endpoint", Reason: "scheme must be http or https"}
+	}
+
+	return &Generator{
+		cfg:      cfg,
+		endpoint: u,
+	}, nil
+}
+
+func (g *Generator) GenerateDownload(_ context.Context, params *s3down.GenerateParams) (*url.URL, error) {
+	if params.VersionID != "" {
+		return nil, fmt.Errorf("%w: s3fs does not support versioning", s3common.ErrNotSupported)
+	}
+
+	query := make(url.Values)
+
+	if !g.cfg.DisableResponseContentType && params.ContentType != "" {
+		query.Set(queryResponseContentType, params.ContentType)
+	}
+
+	if !g.cfg.DisableResponseContentDisposition && params.AttachmentFilename != "" {
+		query.Set(queryResponseContentDisposition, s3common.ComposeContentDisposition(params.AttachmentFilename))
+	}
+
+	u := g.endpoint.JoinPath(g.cfg.Prefix, path.Clean("/"+params.RemotePath)) // clean to avoid escape
+
+	expires := time.Now().Add(params.ExpireIn).Unix()
+	query.Set(queryExpires, strconv.FormatInt(expires, 10))
+	query.Set(querySignature, g.sign(u.EscapedPath(), expires, query))
+
+	u.RawQuery = query.Encode()
+	return u, nil
+}
+
+// verify 校验链接的签名及过期时间
+func (g *Generator) verify(u *url.URL) error {
+	query := u.Query()
+
+	expires, err := strconv.ParseInt(query.Get(queryExpires), 10, 64)
+	if err != nil {
+		return errSignatureMismatch
+	}
+
+	expect := g.sign(u.EscapedPath(), expires, query)
+	if !hmac.Equal([]byte(expect), []byte(query.Get(querySignature))) {
+		return errSignatureMismatch
+	}
+
+	if time.Now().Unix() > expires {
+		return errSignatureExpired
+	}
+
+	return nil
+}
+
+func (g *Generator) sign(escapedPath string, expires int64, query url.Values) string {
+	signText := strings.Join([]string{
+		escapedPath,
+		strconv.FormatInt(expires, 10),
+		query.Get(queryResponseContentType),
+		query.Get(queryResponseContentDisposition),
+	}, "\n")
+
+	mac := hmac.New(sha256.New, []byte(g.cfg.SecretKey))
+	mac.Write([]byte(signText))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// objectName 将请求的 URL Path 还原为 object name，不在 Endpoint 下时返回 false
+func (g *Generator) objectName(urlPath string) (string, bool) {
+	base := path.Clean("/" + g.endpoint.Path)
+	if base != "/" {
+		rest, ok := strings.CutPrefix(urlPath, base+"/")
+		if !ok {
+			return "", false
+		}
+		urlPath = rest
+	}
+
+	return strings.TrimPrefix(path.Clean("/"+urlPath), "/"), true
+}
@@ -0,0 +1,72 @@
+package s3fs
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ix64/s3-go/s3common"
+)
+
+// NewHandler 返回提供 Generator 签名链接下载服务的 http.Handler
+//
+// Handler 需要挂载在 GeneratorConfig.Endpoint 对应的路径下，支持 GET、HEAD、Range 及条件请求
+func NewHandler(c *Client, g *Generator) http.Handler {
+	return &handler{c: c, g: g}
+}
+
+type handler struct {
+	c *Client
+	g *Generator
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeError(w, http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := h.g.verify(r.URL); err != nil {
+		writeError(w, http.StatusForbidden)
+		return
+	}
+
+	objectName, ok := h.g.objectName(r.URL.Path)
+	if !ok || validateObjectName(objectName) != nil {
+		writeError(w, http.StatusNotFound)
+		return
+	}
+
+	f, info, err := h.c.openObject(objectName)
+	if err != nil {
+		if errors.Is(err, s3common.ErrNotFound) {
+			writeError(w, http.StatusNotFound)
+		} else {
+			writeError(w, http.StatusInternalServerError)
+		}
+		return
+	}
+	defer f.Close()
+
+	header := w.Header()
+	for k, v := range info.Metadata {
+		if k != "Content-Length" && k != "Last-Modified" {
+			header[k] = v // Content-Length and Last-Modified are set by http.ServeContent
+		}
+	}
+
+	// signed, can not be tampered
+	query := r.URL.Query()
+	if v := query.Get(queryResponseContentType); v != "" {
+		header.Set("Content-Type", v)
+	}
+	if v := query.Get(queryResponseContentDisposition); v != "" {
+		header.Set("Content-Disposition", v)
+	}
+
+	http.ServeContent(w, r, "", info.LastModified, f)
+}
+
+func writeError(w http.ResponseWriter, code int) {
+	http.Error(w, http.StatusText(code), code)
+}
@@ -0,0 +1,203 @@
+package s3fs
+
+import (
+	"context"
+	"io/fs"
+	"iter"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+
+	"github.com/ix64/s3-go/internal/objutil"
+	"github.com/ix64/s3-go/s3"
+)
+
+// List 分页列举指定前缀下的文件，行为与 s3.Client.List 一致
+func (c *Client) List(ctx context.Context, prefix string, opts *s3.ListOptions) (*s3.ListResult, error) {
+	if opts == nil {
+		opts = &s3.ListOptions{}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := objutil.CheckListPrefix(c.cfg.Prefix, prefix); err != nil {
+		return nil, err
+	}
+
+	startAfter := ""
+	if opts.StartAfter != "" {
+		startAfter = c.composeObjectName(opts.StartAfter)
+	}
+
+	listPrefix := c.composeListPrefix(prefix)
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	names, err := c.objectNames(listPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := objutil.List(names, listPrefix, startAfter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &s3.ListResult{
+		Objects:               make([]minio.ObjectInfo, 0, len(page.Objects)),
+		Prefixes:              make([]string, 0, len(page.Prefixes)),
+		IsTruncated:           page.IsTruncated,
+		NextContinuationToken: page.NextContinuationToken,
+	}
+
+	for _, name := range page.Objects {
+		info, err := c.statObject(name)
+		if err != nil {
+			return nil, err
+		}
+		info.Key = c.trimObjectName(name)
+		ret.Objects = append(ret.Objects, info)
+	}
+
+	for _, p := range page.Prefixes {
+		ret.Prefixes = append(ret.Prefixes, c.trimObjectName(p))
+	}
+
+	return ret, nil
+}
+
+// Walk 递归遍历指定前缀下的所有文件，遍历期间被删除的文件会被跳过
+func (c *Client) Walk(ctx context.Context, prefix string) iter.Seq2[minio.ObjectInfo, error] {
+	return func(yield func(minio.ObjectInfo, error) bool) {
+		if err := objutil.CheckListPrefix(c.cfg.Prefix, prefix); err != nil {
+			yield(minio.ObjectInfo{}, err)
+			return
+		}
+
+		c.mu.RLock()
+		names, err := c.objectNames(c.composeListPrefix(prefix))
+		c.mu.RUnlock()
+
+		if err != nil {
+			yield(minio.ObjectInfo{}, err)
+			return
+		}
+
+		slices.Sort(names)
+
+		for _, name := range names {
+			if err := ctx.Err(); err != nil {
+				yield(minio.ObjectInfo{}, err)
+				return
+			}
+
+			c.mu.RLock()
+			info, err := c.statObject(name)
+			c.mu.RUnlock()
+
+			if err != nil {
+				continue // deleted during walking
+			}
+
+			info.Key = c.trimObjectName(name)
+			if !yield(info, nil) {
+				return
+			}
+		}
+	}
+}
+
+// DeleteMany 批量删除文件，返回删除失败的文件列表
+func (c *Client) DeleteMany(ctx context.Context, paths []string) ([]*s3.DeleteError, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var failed []*s3.DeleteError
+	for _, p := range paths {
+		if err := c.removeObject(c.composeObjectName(p)); err != nil {
+			failed = append(failed, &s3.DeleteError{Path: p, Err: err})
+		}
+	}
+	return failed, nil
+}
+
+// DeletePrefix 删除指定前缀下的所有文件，返回删除失败的文件列表
+// prefix 按字符串前缀匹配，"user/12" 同时删除 "user/123/" 下的文件
+func (c *Client) DeletePrefix(ctx context.Context, prefix string) ([]*s3.DeleteError, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := objutil.CheckListPrefix(c.cfg.Prefix, prefix); err != nil {
+		return nil, err
+	}
+
+	listPrefix := c.composeListPrefix(prefix)
+	if err := objutil.CheckDeletePrefix(listPrefix); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	names, err := c.objectNames(listPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var failed []*s3.DeleteError
+	for _, name := range names {
+		if err := c.removeObject(name); err != nil {
+			failed = append(failed, &s3.DeleteError{Path: c.trimObjectName(name), Err: err})
+		}
+	}
+	return failed, nil
+}
+
+// objectNames 返回指定前缀下的所有 object name，调用方需持有锁
+func (c *Client) objectNames(listPrefix string) ([]string, error) {
+	// only walk the deepest directory covering the prefix
+	dir := path.Dir(listPrefix + "_")
+	if dir == "." {
+		dir = ""
+	}
+
+	var names []string
+	err := filepath.WalkDir(c.dataPath(dir), func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if isNotExist(err) {
+				return nil
+			}
+			return err
+		}
+
+		rel, err := filepath.Rel(c.root, p)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+
+		if d.IsDir() {
+			if name == internalDir {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		if strings.HasPrefix(name, listPrefix) {
+			names = append(names, name)
+		}
+		return nil
+	})
+	return names, err
+}
@@ -0,0 +1,263 @@
+package s3fs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/minio/minio-go/v7"
+
+	"github.com/ix64/s3-go/internal/objutil"
+	"github.com/ix64/s3-go/s3"
+	"github.com/ix64/s3-go/s3common"
+	"github.com/ix64/s3-go/s3down"
+	"github.com/ix64/s3-go/s3up"
+)
+
+// Upload 将 io.Reader 的内容上传到远程的文件
+func (c *Client) Upload(ctx context.Context, remotePath string, file io.Reader, size int64, mime string) error {
+	return c.UploadWithMetadata(ctx, remotePath, file, size, mime, nil)
+}
+
+// UploadWithMetadata 上传文件，并将用户元数据保存在元数据文件中，读取时以 "X-Amz-Meta-" 响应头返回
+func (c *Client) UploadWithMetadata(ctx context.Context, remotePath string, file io.Reader, size int64, mime string, userMetadata map[string]string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return c.writeObject(c.composeObjectName(remotePath), file, size, &metadata{
+		ContentType:  mime,
+		UserMetadata: userMetadata,
+	})
+}
+
+// UploadFile 将本地文件上传到远程
+func (c *Client) UploadFile(ctx context.Context, remotePath string, localPath string) error {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return c.Upload(ctx, remotePath, f, -1, mime.TypeByExtension(path.Ext(remotePath)))
+}
+
+// Download 获取文件内容，返回的 io.ReadCloser 为打开的文件，需要调用方关闭
+func (c *Client) Download(ctx context.Context, remotePath string) (io.ReadCloser, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f, _, err := c.openObject(c.composeObjectName(remotePath))
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// DownloadWithOptions 按范围或条件获取文件内容，行为与 s3.Client.DownloadWithOptions 一致
+func (c *Client) DownloadWithOptions(ctx context.Context, remotePath string, opts *s3.DownloadOptions) (*s3.DownloadResult, error) {
+	if opts == nil {
+		opts = &s3.DownloadOptions{}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	objectName := c.composeObjectName(remotePath)
+	f, info, err := c.openObject(objectName)
+	if err != nil {
+		return nil, err
+	}
+
+	ret, err := downloadResult(f, info, opts, objectName)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	ret.Info.Key = c.trimObjectName(objectName)
+	return ret, nil
+}
+
+func downloadResult(f *os.File, info minio.ObjectInfo, opts *s3.DownloadOptions, objectName string) (*s3.DownloadResult, error) {
+	if err := objutil.CheckConditions(info.ETag, info.LastModified, opts, objectName); err != nil {
+		return nil, err
+	}
+
+	total := info.Size
+	start, end, partial, err := objutil.ResolveRange(opts, total, objectName)
+	if err != nil {
+		return nil, err
+	}
+
+	info.Size = end - start
+
+	ret := &s3.DownloadResult{
+		Body: readCloser{
+			Reader: io.NewSectionReader(f, start, end-start),
+			Closer: f,
+		},
+		Info:      info,
+		TotalSize: total,
+	}
+	if partial {
+		ret.ContentRange = objutil.ContentRange(start, end, total)
+	}
+	return ret, nil
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// DownloadFile 下载文件到指定的本地路径，先写入临时文件再重命名
+func (c *Client) DownloadFile(ctx context.Context, remotePath string, localPath string) (err error) {
+	r, err := c.Download(ctx, remotePath)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	if err := os.MkdirAll(filepath.Dir(localPath), 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(localPath), filepath.Base(localPath)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer removeOnError(f.Name(), &err)
+	defer f.Close()
+
+	if _, err := io.Copy(f, r); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), localPath)
+}
+
+// Stat 获取文件信息，与 s3.Client 一致，Key 为包含 Config.Prefix 的 object name
+func (c *Client) Stat(ctx context.Context, remotePath string) (minio.ObjectInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return minio.ObjectInfo{}, err
+	}
+
+	objectName := c.composeObjectName(remotePath)
+	if err := validateObjectName(objectName); err != nil {
+		return minio.ObjectInfo{}, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.statObject(objectName)
+}
+
+// Delete 删除文件，文件不存在时不返回错误
+func (c *Client) Delete(ctx context.Context, remotePath string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.removeObject(c.composeObjectName(remotePath))
+}
+
+// Copy 复制文件，保留 Content-Type 及用户元数据
+func (c *Client) Copy(ctx context.Context, oldPath string, newPath string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	f, info, err := c.openObject(c.composeObjectName(oldPath))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return c.writeObject(c.composeObjectName(newPath), f, info.Size, &metadata{
+		ContentType:  info.ContentType,
+		UserMetadata: info.UserMetadata,
+	})
+}
+
+// Move 移动文件，直接重命名对象文件及元数据文件
+func (c *Client) Move(ctx context.Context, oldPath, newPath string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	srcName, dstName := c.composeObjectName(oldPath), c.composeObjectName(newPath)
+	for _, name := range []string{srcName, dstName} {
+		if err := validateObjectName(name); err != nil {
+			return err
+		}
+	}
+
+	if srcName == dstName {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, err := c.statObject(srcName); err != nil {
+		return err
+	}
+
+	// move metadata by copying, so that source stays intact if renaming content fails
+	metaTemp, err := c.createTemp()
+	if err != nil {
+		return err
+	}
+	defer os.Remove(metaTemp.Name()) // no-op after committed
+
+	if buf, err := os.ReadFile(c.metaPath(srcName)); err == nil {
+		if _, err := metaTemp.Write(buf); err != nil {
+			_ = metaTemp.Close()
+			return err
+		}
+	}
+	if err := metaTemp.Close(); err != nil {
+		return err
+	}
+
+	if err := c.commit(dstName, c.dataPath(srcName), metaTemp.Name()); err != nil {
+		return err
+	}
+
+	if err := os.Remove(c.metaPath(srcName)); err != nil && !isNotExist(err) {
+		return err
+	}
+	c.pruneDirs(srcName)
+	return nil
+}
+
+// GenerateDownload 生成下载链接，需要先通过 SetDownloadGenerator 设置生成器
+func (c *Client) GenerateDownload(ctx context.Context, params *s3down.GenerateParams) (*url.URL, error) {
+	if c.download == nil {
+		return nil, fmt.Errorf("%w: download generator is not set", s3common.ErrNotSupported)
+	}
+	return c.download.GenerateDownload(ctx, params)
+}
+
+// GenerateUpload 生成上传链接，需要先通过 SetUploadGenerator 设置生成器
+func (c *Client) GenerateUpload(ctx context.Context, param *s3up.GenerateParams) (*s3up.GenerateResult, error) {
+	if c.upload == nil {
+		return nil, fmt.Errorf("%w: upload generator is not set", s3common.ErrNotSupported)
+	}
+	return c.upload.GenerateUpload(ctx, param)
+}
@@ -0,0 +1,205 @@
+package s3mem
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"hash/crc32"
+	"maps"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+
+	"github.com/ix64/s3-go/internal/objutil"
+	"github.com/ix64/s3-go/s3"
+	"github.com/ix64/s3-go/s3common"
+	"github.com/ix64/s3-go/s3down"
+	"github.com/ix64/s3-go/s3up"
+)
+
+// defaultEndpoint 未配置 Endpoint 时，预签名链接使用的地址
+const defaultEndpoint = "http://s3mem.invalid"
+
+type Config struct {
+	// optional, same as s3.Config.Prefix
+	Prefix string `json:"prefix"`
+
+	// optional, base url of generated upload and download urls, default to "http://s3mem.invalid"
+	Endpoint string `json:"endpoint"`
+}
+
+// Client 基于内存的 s3.Storage 实现，用于单元测试
+//
+// 与 s3.Client 一样处理 Config.Prefix，保存自定义元数据及 Content-Type 等标准头，并计算 ETag 及 sha256、crc32c 校验和，
+// 返回的错误同样可使用 errors.Is 判断，例如 s3.ErrNotFound
+type Client struct {
+	cfg      *Config
+	endpoint *url.URL
+
+	mu      sync.RWMutex
+	objects map[string]*object // key: object name with prefix
+
+	upload   s3up.Generator
+	download s3down.Generator
+}
+
+var _ s3.Storage = (*Client)(nil)
+
+type object struct {
+	data         []byte
+	etag         string
+	lastModified time.Time
+
+	contentType        string
+	cacheControl       string
+	contentDisposition string
+	contentEncoding    string
+	contentLanguage    string
+	expires            time.Time
+	storageClass       string
+	userMetadata       map[string]string
+
+	checksumSHA256 string
+	checksumCRC32C string
+}
+
+// NewClient 创建空的内存存储
+func NewClient(cfg *Config) (*Client, error) {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = defaultEndpoint
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, &s3common.ConfigError{Field: "endpoint", Reason: "is invalid: " + err.Error()}
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, &s3common.ConfigError{Field: "endpoint", Reason: "scheme must be http or https"}
+	}
+
+	return &Client{
+		cfg:      cfg,
+		endpoint: u,
+		objects:  make(map[string]*object),
+	}, nil
+}
+
+// SetDownloadGenerator 可设置自定义的下载链接生成器，未设置时生成指向 Config.Endpoint 的链接
+func (c *Client) SetDownloadGenerator(g s3down.Generator) {
+	c.download = g
+}
+
+// SetUploadGenerator 可设置自定义的上传链接生成器，未设置时生成指向 Config.Endpoint 的 PUT 请求
+func (c *Client) SetUploadGenerator(g s3up.Generator) {
+	c.upload = g
+}
+
+func (c *Client) composeObjectName(remotePath string) string {
+	return objutil.ComposeObjectName(c.cfg.Prefix, remotePath)
+}
+
+func (c *Client) composeListPrefix(prefix string) string {
+	return objutil.ComposeListPrefix(c.cfg.Prefix, prefix)
+}
+
+func (c *Client) trimObjectName(objectName string) string {
+	return objutil.TrimObjectName(c.cfg.Prefix, objectName)
+}
+
+// newObject 按 opts 保存标准头及自定义元数据，与 s3mem 无关的标签、对象锁定及加密选项被忽略
+func newObject(data []byte, opts *s3.PutOptions) *object {
+	contentType := opts.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	etag := md5.Sum(data)
+	sha := sha256.Sum256(data)
+	crc := crc32.Checksum(data, crc32.MakeTable(crc32.Castagnoli))
+
+	return &object{
+		data:         data,
+		etag:         hex.EncodeToString(etag[:]),
+		lastModified: time.Now().UTC().Truncate(time.Millisecond),
+
+		contentType:        contentType,
+		cacheControl:       opts.CacheControl,
+		contentDisposition: opts.ContentDisposition,
+		contentEncoding:    opts.ContentEncoding,
+		contentLanguage:    opts.ContentLanguage,
+		expires:            opts.Expires,
+		storageClass:       opts.StorageClass,
+		userMetadata:       maps.Clone(opts.UserMetadata),
+
+		checksumSHA256: base64.StdEncoding.EncodeToString(sha[:]),
+		checksumCRC32C: encodeCRC32(crc),
+	}
+}
+
+// encodeCRC32 与 x-amz-checksum-crc32c 一致，大端序后 base64 编码
+func encodeCRC32(crc uint32) string {
+	return base64.StdEncoding.EncodeToString(binary.BigEndian.AppendUint32(nil, crc))
+}
+
+func (o *object) info(key string) minio.ObjectInfo {
+	header := http.Header{}
+	header.Set("Content-Type", o.contentType)
+	header.Set("Content-Length", strconv.Itoa(len(o.data)))
+	header.Set("Last-Modified", o.lastModified.Format(http.TimeFormat))
+	header.Set("ETag", `"`+o.etag+`"`)
+
+	for k, v := range map[string]string{
+		"Cache-Control":       o.cacheControl,
+		"Content-Disposition": o.contentDisposition,
+		"Content-Encoding":    o.contentEncoding,
+		"Content-Language":    o.contentLanguage,
+		"X-Amz-Storage-Class": o.storageClass,
+	} {
+		if v != "" {
+			header.Set(k, v)
+		}
+	}
+	if !o.expires.IsZero() {
+		header.Set("Expires", o.expires.UTC().Format(http.TimeFormat))
+	}
+
+	userMetadata := make(minio.StringMap, len(o.userMetadata))
+	for k, v := range o.userMetadata {
+		header.Set("X-Amz-Meta-"+k, v)
+		userMetadata[k] = v
+	}
+
+	return minio.ObjectInfo{
+		Key:          key,
+		ETag:         o.etag,
+		Size:         int64(len(o.data)),
+		LastModified: o.lastModified,
+		ContentType:  o.contentType,
+		Expires:      o.expires,
+		StorageClass: o.storageClass,
+		Metadata:     header,
+		UserMetadata: userMetadata,
+
+		ChecksumSHA256: o.checksumSHA256,
+		ChecksumCRC32C: o.checksumCRC32C,
+		ChecksumMode:   "FULL_OBJECT",
+	}
+}
+
+func (o *object) clone() *object {
+	ret := *o
+	ret.lastModified = time.Now().UTC().Truncate(time.Millisecond)
+	return &ret
+}
@@ -0,0 +1,188 @@
+package s3mem_test
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ix64/s3-go/s3"
+	"github.com/ix64/s3-go/s3down"
+	"github.com/ix64/s3-go/s3mem"
+)
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+
+	c, err := s3mem.NewClient(&s3mem.Config{Prefix: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content := "hello, s3mem"
+	err = c.UploadWithOptions(ctx, "docs/hello.txt", strings.NewReader(content), int64(len(content)), &s3.PutOptions{
+		ContentType:  "text/plain",
+		UserMetadata: map[string]string{"Owner": "unit-test"},
+		CacheControl: "max-age=60",
+	})
+	assert.NoError(t, err)
+
+	t.Run("Stat", func(t *testing.T) {
+		info, err := c.Stat(ctx, "docs/hello.txt")
+		assert.NoError(t, err)
+
+		sum := md5.Sum([]byte(content))
+		assert.Equal(t, "app/docs/hello.txt", info.Key)
+		assert.Equal(t, hex.EncodeToString(sum[:]), info.ETag)
+		assert.Equal(t, int64(len(content)), info.Size)
+		assert.Equal(t, "text/plain", info.ContentType)
+		assert.Equal(t, "unit-test", info.UserMetadata["Owner"])
+		assert.Equal(t, "unit-test", info.Metadata.Get("X-Amz-Meta-Owner"))
+		assert.Equal(t, "max-age=60", info.Metadata.Get("Cache-Control"))
+		assert.NotEmpty(t, info.ChecksumSHA256)
+		assert.NotEmpty(t, info.ChecksumCRC32C)
+	})
+
+	t.Run("DownloadRange", func(t *testing.T) {
+		ret, err := c.DownloadWithOptions(ctx, "docs/hello.txt", &s3.DownloadOptions{Offset: 7, Length: 100})
+		assert.NoError(t, err)
+
+		buf, err := io.ReadAll(ret.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, "s3mem", string(buf))
+		assert.Equal(t, "bytes 7-11/12", ret.ContentRange)
+		assert.Equal(t, int64(len(content)), ret.TotalSize)
+
+		_, err = c.DownloadWithOptions(ctx, "docs/hello.txt", &s3.DownloadOptions{Offset: 100})
+		assert.ErrorIs(t, err, s3.ErrInvalidRange)
+	})
+
+	t.Run("DownloadConditional", func(t *testing.T) {
+		info, err := c.Stat(ctx, "docs/hello.txt")
+		assert.NoError(t, err)
+
+		_, err = c.DownloadWithOptions(ctx, "docs/hello.txt", &s3.DownloadOptions{IfNoneMatch: info.ETag})
+		assert.ErrorIs(t, err, s3.ErrNotModified)
+
+		_, err = c.DownloadWithOptions(ctx, "docs/hello.txt", &s3.DownloadOptions{IfMatch: "mismatch"})
+		assert.ErrorIs(t, err, s3.ErrPreconditionFailed)
+	})
+
+	t.Run("CopyMove", func(t *testing.T) {
+		assert.NoError(t, c.Copy(ctx, "docs/hello.txt", "docs/copy.txt"))
+		assert.NoError(t, c.Move(ctx, "docs/copy.txt", "archive/moved.txt"))
+
+		_, err := c.Stat(ctx, "docs/copy.txt")
+		assert.ErrorIs(t, err, s3.ErrNotFound)
+
+		info, err := c.Stat(ctx, "archive/moved.txt")
+		assert.NoError(t, err)
+		assert.Equal(t, "text/plain", info.ContentType)
+		assert.Equal(t, "unit-test", info.UserMetadata["Owner"])
+		assert.Equal(t, "max-age=60", info.Metadata.Get("Cache-Control"))
+
+		assert.ErrorIs(t, c.Copy(ctx, "missing.txt", "other.txt"), s3.ErrNotFound)
+	})
+
+	t.Run("List", func(t *testing.T) {
+		ret, err := c.List(ctx, "", nil)
+		assert.NoError(t, err)
+		assert.Empty(t, ret.Objects)
+		assert.Equal(t, []string{"archive/", "docs/"}, ret.Prefixes)
+
+		// one entry per page
+		var keys []string
+		opts := &s3.ListOptions{Recursive: true, MaxKeys: 1}
+		for {
+			ret, err := c.List(ctx, "", opts)
+			assert.NoError(t, err)
+			for _, obj := range ret.Objects {
+				keys = append(keys, obj.Key)
+			}
+			if !ret.IsTruncated {
+				break
+			}
+			opts.ContinuationToken = ret.NextContinuationToken
+		}
+		assert.Equal(t, []string{"archive/moved.txt", "docs/hello.txt"}, keys)
+	})
+
+	t.Run("DeletePrefix", func(t *testing.T) {
+		failed, err := c.DeletePrefix(ctx, "archive/")
+		assert.NoError(t, err)
+		assert.Empty(t, failed)
+
+		var keys []string
+		for obj, err := range c.Walk(ctx, "") {
+			assert.NoError(t, err)
+			keys = append(keys, obj.Key)
+		}
+		assert.Equal(t, []string{"docs/hello.txt"}, keys)
+	})
+
+	t.Run("DeletePrefixWholeBucket", func(t *testing.T) {
+		root, err := s3mem.NewClient(&s3mem.Config{})
+		assert.NoError(t, err)
+		assert.NoError(t, root.Upload(ctx, "a.txt", strings.NewReader("a"), 1, "text/plain"))
+
+		for _, prefix := range []string{"", "/"} {
+			_, err = root.DeletePrefix(ctx, prefix)
+			assert.ErrorIs(t, err, s3.ErrInvalidArgument)
+		}
+
+		_, err = root.Stat(ctx, "a.txt")
+		assert.NoError(t, err)
+	})
+
+	t.Run("DeletePrefixStringMatch", func(t *testing.T) {
+		m, err := s3mem.NewClient(&s3mem.Config{})
+		assert.NoError(t, err)
+		for _, p := range []string{"user/12.txt", "user/12/a.txt", "user/123/b.txt"} {
+			assert.NoError(t, m.Upload(ctx, p, strings.NewReader("a"), 1, "text/plain"))
+		}
+
+		_, err = m.DeletePrefix(ctx, "user/12/")
+		assert.NoError(t, err)
+		_, err = m.Stat(ctx, "user/123/b.txt")
+		assert.NoError(t, err)
+
+		// without trailing "/", siblings sharing the prefix are deleted as well
+		_, err = m.DeletePrefix(ctx, "user/12")
+		assert.NoError(t, err)
+		for obj, err := range m.Walk(ctx, "") {
+			assert.NoError(t, err)
+			assert.Fail(t, "unexpected object", obj.Key)
+		}
+	})
+
+	t.Run("PrefixOutOfScope", func(t *testing.T) {
+		for _, prefix := range []string{"..", "../other/", "a/../../other", "/../app-other/"} {
+			failed, err := c.DeletePrefix(ctx, prefix)
+			assert.ErrorIs(t, err, s3.ErrInvalidArgument, prefix)
+			assert.Empty(t, failed)
+
+			_, err = c.List(ctx, prefix, nil)
+			assert.ErrorIs(t, err, s3.ErrInvalidArgument, prefix)
+
+			walked := false
+			for _, err := range c.Walk(ctx, prefix) {
+				walked = true
+				assert.ErrorIs(t, err, s3.ErrInvalidArgument, prefix)
+			}
+			assert.True(t, walked, prefix)
+		}
+
+		_, err = c.Stat(ctx, "docs/hello.txt")
+		assert.NoError(t, err)
+	})
+
+	t.Run("GenerateDownload", func(t *testing.T) {
+		u, err := c.GenerateDownload(ctx, &s3down.GenerateParams{RemotePath: "docs/hello.txt"})
+		assert.NoError(t, err)
+		assert.Equal(t, "/app/docs/hello.txt", u.Path)
+	})
+}
@@ -0,0 +1,138 @@
+package s3mem
+
+import (
+	"context"
+	"iter"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+
+	"github.com/ix64/s3-go/internal/objutil"
+	"github.com/ix64/s3-go/s3"
+)
+
+// List 分页列举指定前缀下的文件，行为与 s3.Client.List 一致
+func (c *Client) List(ctx context.Context, prefix string, opts *s3.ListOptions) (*s3.ListResult, error) {
+	if opts == nil {
+		opts = &s3.ListOptions{}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := objutil.CheckListPrefix(c.cfg.Prefix, prefix); err != nil {
+		return nil, err
+	}
+
+	startAfter := ""
+	if opts.StartAfter != "" {
+		startAfter = c.composeObjectName(opts.StartAfter)
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	page, err := objutil.List(slices.Collect(maps.Keys(c.objects)), c.composeListPrefix(prefix), startAfter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &s3.ListResult{
+		Objects:               make([]minio.ObjectInfo, 0, len(page.Objects)),
+		Prefixes:              make([]string, 0, len(page.Prefixes)),
+		IsTruncated:           page.IsTruncated,
+		NextContinuationToken: page.NextContinuationToken,
+	}
+
+	for _, name := range page.Objects {
+		ret.Objects = append(ret.Objects, c.objects[name].info(c.trimObjectName(name)))
+	}
+
+	for _, p := range page.Prefixes {
+		ret.Prefixes = append(ret.Prefixes, c.trimObjectName(p))
+	}
+
+	return ret, nil
+}
+
+// Walk 递归遍历指定前缀下的所有文件，遍历的是调用时的快照
+func (c *Client) Walk(ctx context.Context, prefix string) iter.Seq2[minio.ObjectInfo, error] {
+	return func(yield func(minio.ObjectInfo, error) bool) {
+		if err := objutil.CheckListPrefix(c.cfg.Prefix, prefix); err != nil {
+			yield(minio.ObjectInfo{}, err)
+			return
+		}
+
+		c.mu.RLock()
+		names := c.sortedNames(c.composeListPrefix(prefix))
+		infos := make([]minio.ObjectInfo, 0, len(names))
+		for _, name := range names {
+			infos = append(infos, c.objects[name].info(c.trimObjectName(name)))
+		}
+		c.mu.RUnlock()
+
+		for _, info := range infos {
+			if err := ctx.Err(); err != nil {
+				yield(minio.ObjectInfo{}, err)
+				return
+			}
+			if !yield(info, nil) {
+				return
+			}
+		}
+	}
+}
+
+// DeleteMany 批量删除文件，文件不存在时不视为失败
+func (c *Client) DeleteMany(ctx context.Context, paths []string) ([]*s3.DeleteError, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, p := range paths {
+		delete(c.objects, c.composeObjectName(p))
+	}
+	return nil, nil
+}
+
+// DeletePrefix 删除指定前缀下的所有文件，prefix 按字符串前缀匹配，"user/12" 同时删除 "user/123/" 下的文件
+func (c *Client) DeletePrefix(ctx context.Context, prefix string) ([]*s3.DeleteError, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := objutil.CheckListPrefix(c.cfg.Prefix, prefix); err != nil {
+		return nil, err
+	}
+
+	listPrefix := c.composeListPrefix(prefix)
+	if err := objutil.CheckDeletePrefix(listPrefix); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, name := range c.sortedNames(listPrefix) {
+		delete(c.objects, name)
+	}
+	return nil, nil
+}
+
+// sortedNames 按字典序返回指定前缀下的 object name，调用方需持有锁
+func (c *Client) sortedNames(listPrefix string) []string {
+	names := make([]string, 0)
+	for name := range c.objects {
+		if strings.HasPrefix(name, listPrefix) {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
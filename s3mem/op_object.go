@@ -0,0 +1,273 @@
+package s3mem
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+
+	"github.com/ix64/s3-go/internal/objutil"
+	"github.com/ix64/s3-go/s3"
+	"github.com/ix64/s3-go/s3common"
+	"github.com/ix64/s3-go/s3down"
+	"github.com/ix64/s3-go/s3up"
+)
+
+// Upload 将 io.Reader 的内容上传到远程的文件
+//
+// size 为 -1 时读取到 EOF，否则内容长度必须与 size 一致
+func (c *Client) Upload(ctx context.Context, remotePath string, file io.Reader, size int64, mime string) error {
+	return c.UploadWithOptions(ctx, remotePath, file, size, &s3.PutOptions{ContentType: mime})
+}
+
+// UploadWithOptions 与 s3.Client.UploadWithOptions 一致，保存自定义元数据及标准头，忽略标签、对象锁定及加密选项
+func (c *Client) UploadWithOptions(ctx context.Context, remotePath string, file io.Reader, size int64, opts *s3.PutOptions) error {
+	if opts == nil {
+		opts = &s3.PutOptions{}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var data []byte
+	var err error
+	if size < 0 {
+		data, err = io.ReadAll(file)
+	} else {
+		data = make([]byte, size)
+		_, err = io.ReadFull(file, data)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read content: %w", err)
+	}
+
+	c.put(c.composeObjectName(remotePath), newObject(data, opts))
+	return nil
+}
+
+// UploadFile 将本地文件上传到远程
+func (c *Client) UploadFile(ctx context.Context, remotePath string, localPath string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(localPath)
+	if err != nil {
+		return err
+	}
+
+	c.put(c.composeObjectName(remotePath), newObject(data, &s3.PutOptions{ContentType: mime.TypeByExtension(path.Ext(remotePath))}))
+	return nil
+}
+
+// Download 获取文件内容，返回的内容为调用时的快照
+func (c *Client) Download(ctx context.Context, remotePath string) (io.ReadCloser, error) {
+	ret, err := c.DownloadWithOptions(ctx, remotePath, nil)
+	if err != nil {
+		return nil, err
+	}
+	return ret.Body, nil
+}
+
+// DownloadWithOptions 按范围或条件获取文件内容，行为与 s3.Client.DownloadWithOptions 一致
+func (c *Client) DownloadWithOptions(ctx context.Context, remotePath string, opts *s3.DownloadOptions) (*s3.DownloadResult, error) {
+	if opts == nil {
+		opts = &s3.DownloadOptions{}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	objectName := c.composeObjectName(remotePath)
+	obj, err := c.get(objectName)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := objutil.CheckConditions(obj.etag, obj.lastModified, opts, objectName); err != nil {
+		return nil, err
+	}
+
+	total := int64(len(obj.data))
+	start, end, partial, err := objutil.ResolveRange(opts, total, objectName)
+	if err != nil {
+		return nil, err
+	}
+
+	info := obj.info(c.trimObjectName(objectName))
+	info.Size = end - start
+
+	ret := &s3.DownloadResult{
+		Body:      io.NopCloser(bytes.NewReader(obj.data[start:end])),
+		Info:      info,
+		TotalSize: total,
+	}
+	if partial {
+		ret.ContentRange = objutil.ContentRange(start, end, total)
+	}
+
+	return ret, nil
+}
+
+// DownloadFile 下载文件到指定的本地路径
+func (c *Client) DownloadFile(ctx context.Context, remotePath string, localPath string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	obj, err := c.get(c.composeObjectName(remotePath))
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(localPath), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(localPath, obj.data, 0o644)
+}
+
+// Stat 获取文件信息，与 s3.Client 一致，Key 为包含 Config.Prefix 的 object name
+func (c *Client) Stat(ctx context.Context, remotePath string) (minio.ObjectInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return minio.ObjectInfo{}, err
+	}
+
+	objectName := c.composeObjectName(remotePath)
+	obj, err := c.get(objectName)
+	if err != nil {
+		return minio.ObjectInfo{}, err
+	}
+	return obj.info(objectName), nil
+}
+
+// Delete 删除文件，文件不存在时不返回错误
+func (c *Client) Delete(ctx context.Context, remotePath string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.objects, c.composeObjectName(remotePath))
+	return nil
+}
+
+// Copy 远程复制文件，保留自定义元数据及标准头
+func (c *Client) Copy(ctx context.Context, oldPath string, newPath string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	srcName := c.composeObjectName(oldPath)
+	obj, ok := c.objects[srcName]
+	if !ok {
+		return objutil.NotFound(srcName)
+	}
+
+	c.objects[c.composeObjectName(newPath)] = obj.clone()
+	return nil
+}
+
+// Move 远程移动文件（复制后删除）
+func (c *Client) Move(ctx context.Context, oldPath, newPath string) error {
+	if err := c.Copy(ctx, oldPath, newPath); err != nil {
+		return err
+	}
+	return c.Delete(ctx, oldPath)
+}
+
+// GenerateDownload 生成下载链接，未设置 DownloadGenerator 时返回 Config.Endpoint 下的链接
+func (c *Client) GenerateDownload(ctx context.Context, params *s3down.GenerateParams) (*url.URL, error) {
+	if c.download != nil {
+		return c.download.GenerateDownload(ctx, params)
+	}
+
+	if params.VersionID != "" {
+		return nil, fmt.Errorf("%w: s3mem does not support versioning", s3.ErrNotSupported)
+	}
+
+	u := c.objectURL(params.RemotePath)
+
+	query := u.Query()
+	query.Set("expires", strconv.FormatInt(time.Now().Add(params.ExpireIn).Unix(), 10))
+	if params.ContentType != "" {
+		query.Set("response-content-type", params.ContentType)
+	}
+	if params.AttachmentFilename != "" {
+		query.Set("response-content-disposition", s3common.ComposeContentDisposition(params.AttachmentFilename))
+	}
+	u.RawQuery = query.Encode()
+
+	return u, nil
+}
+
+// GenerateUpload 生成上传链接，未设置 UploadGenerator 时返回 Config.Endpoint 下的 PUT 请求
+func (c *Client) GenerateUpload(ctx context.Context, param *s3up.GenerateParams) (*s3up.GenerateResult, error) {
+	if c.upload != nil {
+		return c.upload.GenerateUpload(ctx, param)
+	}
+
+	u := c.objectURL(param.RemotePath)
+
+	query := u.Query()
+	query.Set("expires", strconv.FormatInt(time.Now().Add(param.ExpireIn).Unix(), 10))
+	u.RawQuery = query.Encode()
+
+	header := http.Header{}
+	header.Set("Content-Length", strconv.FormatInt(param.Size, 10))
+	if param.ContentType != "" {
+		header.Set("Content-Type", param.ContentType)
+	}
+	if param.AttachmentFilename != "" {
+		header.Set("Content-Disposition", s3common.ComposeContentDisposition(param.AttachmentFilename))
+	}
+	for k, v := range param.Metadata {
+		header.Set("X-Amz-Meta-"+k, v)
+	}
+
+	return &s3up.GenerateResult{
+		Method: http.MethodPut,
+		URL:    u,
+		Header: header,
+	}, nil
+}
+
+func (c *Client) objectURL(remotePath string) *url.URL {
+	ret := *c.endpoint // copy
+	ret.Path = path.Join("/", ret.Path, c.composeObjectName(remotePath))
+	return &ret
+}
+
+func (c *Client) get(objectName string) (*object, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	obj, ok := c.objects[objectName]
+	if !ok {
+		return nil, objutil.NotFound(objectName)
+	}
+	return obj, nil
+}
+
+func (c *Client) put(objectName string, obj *object) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.objects[objectName] = obj
+}
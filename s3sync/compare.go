@@ -0,0 +1,126 @@
+package s3sync
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"hash"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+
+	"github.com/ix64/s3-go/s3"
+)
+
+// compare 比较本地文件与远程对象，返回同步的原因，无需同步时返回空
+//
+// upload 为 true 时源为本地文件，否则源为远程对象，仅源文件较新时视为修改
+func compare(
+	ctx context.Context,
+	storage s3.Storage,
+	mode CompareMode,
+	local localFile,
+	remote minio.ObjectInfo,
+	localPath string,
+	remotePath string,
+	upload bool,
+) (string, error) {
+	if local.Size != remote.Size {
+		return "size changed", nil
+	}
+
+	switch mode {
+	case CompareSize:
+		return "", nil
+
+	case CompareChecksum:
+		same, err := sameChecksum(ctx, storage, localPath, remote, remotePath)
+		if err != nil || same {
+			return "", err
+		}
+		return "checksum changed", nil
+
+	default:
+		newer := local.ModTime.After(remote.LastModified)
+		if !upload {
+			newer = remote.LastModified.After(local.ModTime)
+		}
+		if newer {
+			return "modified", nil
+		}
+		return "", nil
+	}
+}
+
+// sameChecksum ETag 为 MD5 时与本地文件的 MD5 比较，否则与对象完整的 sha256、crc64nvme、crc32c、crc32 校验和比较，
+// 分片上传的对象默认带有完整的 crc32c 校验和
+func sameChecksum(ctx context.Context, storage s3.Storage, localPath string, remote minio.ObjectInfo, remotePath string) (bool, error) {
+	etag := strings.Trim(remote.ETag, `"`)
+	if isMD5(etag) {
+		md5Sum, err := hashFile(localPath, md5.New())
+		if err != nil {
+			return false, err
+		}
+		if etag == hex.EncodeToString(md5Sum) {
+			return true, nil
+		}
+	}
+
+	// listing does not return checksum, stat is required
+	info, err := storage.Stat(ctx, remotePath)
+	if err != nil {
+		return false, err
+	}
+
+	checksums := []struct {
+		typ    minio.ChecksumType
+		expect string
+	}{
+		{minio.ChecksumSHA256, info.ChecksumSHA256},
+		{minio.ChecksumCRC64NVME, info.ChecksumCRC64NVME},
+		{minio.ChecksumCRC32C, info.ChecksumCRC32C},
+		{minio.ChecksumCRC32, info.ChecksumCRC32},
+	}
+	for _, c := range checksums {
+		if !isFullObjectChecksum(c.expect, info) {
+			continue
+		}
+		sum, err := hashFile(localPath, c.typ.Hasher())
+		if err != nil {
+			return false, err
+		}
+		return c.expect == base64.StdEncoding.EncodeToString(sum), nil
+	}
+
+	return false, nil // unable to verify, treat as changed
+}
+
+// isFullObjectChecksum 分片上传的组合校验和（包含 "-" 或 COMPOSITE 模式）不是完整内容的校验和
+func isFullObjectChecksum(checksum string, info minio.ObjectInfo) bool {
+	return checksum != "" && !strings.Contains(checksum, "-") && info.ChecksumMode != "COMPOSITE"
+}
+
+func hashFile(localPath string, h hash.Hash) ([]byte, error) {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// isMD5 单次上传且未使用 SSE-KMS、SSE-C 加密时，ETag 为内容的 MD5
+func isMD5(etag string) bool {
+	if len(etag) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(etag)
+	return err == nil
+}
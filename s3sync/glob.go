@@ -0,0 +1,110 @@
+package s3sync
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ix64/s3-go/s3common"
+)
+
+// matcher 按 Include、Exclude 过滤以 "/" 分隔的相对路径
+type matcher struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+func newMatcher(include, exclude []string) (*matcher, error) {
+	m := &matcher{}
+	for _, p := range include {
+		re, err := compileGlob(p)
+		if err != nil {
+			return nil, err
+		}
+		m.include = append(m.include, re)
+	}
+	for _, p := range exclude {
+		re, err := compileGlob(p)
+		if err != nil {
+			return nil, err
+		}
+		m.exclude = append(m.exclude, re)
+	}
+	return m, nil
+}
+
+// match Exclude 优先于 Include，Include 为空时匹配全部
+func (m *matcher) match(rel string) bool {
+	for _, re := range m.exclude {
+		if re.MatchString(rel) {
+			return false
+		}
+	}
+	if len(m.include) == 0 {
+		return true
+	}
+	for _, re := range m.include {
+		if re.MatchString(rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// compileGlob 将 glob 转换为正则表达式
+//
+// "*" 匹配除 "/" 外的任意字符，"**" 匹配任意层级目录，"?" 匹配单个字符，支持 "[...]"；
+// 不含 "/" 的模式匹配任意目录下的文件名，例如 "*.tmp" 等价于 "**/*.tmp"；
+// 以 "/" 结尾的模式匹配目录下的全部文件，例如 "cache/" 等价于 "cache/**"
+func compileGlob(pattern string) (*regexp.Regexp, error) {
+	p := strings.TrimPrefix(pattern, "/")
+	if p == "" {
+		return nil, fmt.Errorf("%w: empty glob pattern", s3common.ErrInvalidArgument)
+	}
+	if strings.HasSuffix(p, "/") {
+		p += "**"
+	}
+
+	var b strings.Builder
+	b.WriteString("^")
+	if !strings.Contains(pattern, "/") {
+		b.WriteString("(?:.*/)?")
+	}
+
+	for i := 0; i < len(p); i++ {
+		switch ch := p[i]; ch {
+		case '*':
+			if strings.HasPrefix(p[i:], "**/") {
+				b.WriteString("(?:.*/)?")
+				i += 2
+			} else if strings.HasPrefix(p[i:], "**") {
+				b.WriteString(".*")
+				i++
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(p[i+1:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("%w: invalid glob pattern %q", s3common.ErrInvalidArgument, pattern)
+			}
+			class := p[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		default:
+			b.WriteString(regexp.QuoteMeta(string(ch)))
+		}
+	}
+	b.WriteString("$")
+
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid glob pattern %q: %w", s3common.ErrInvalidArgument, pattern, err)
+	}
+	return re, nil
+}
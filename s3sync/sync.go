@@ -0,0 +1,439 @@
+package s3sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+
+	"github.com/ix64/s3-go/s3"
+	"github.com/ix64/s3-go/s3common"
+)
+
+const defaultConcurrency = 4
+
+// tempSuffixes 下载中的临时文件及断点信息，不参与同步
+var tempSuffixes = []string{".s3download", ".s3download.json"}
+
+type CompareMode string
+
+const (
+	// CompareSizeModTime 大小不同，或源文件的修改时间晚于目标文件时同步
+	CompareSizeModTime CompareMode = "size_mtime"
+
+	// CompareSize 仅在大小不同时同步
+	CompareSize CompareMode = "size"
+
+	// CompareChecksum 大小不同，或本地文件的 MD5 与 ETag、sha256 与对象校验和不一致时同步，需要读取本地文件
+	//
+	// ETag 不是 MD5（分片上传、SSE-KMS、SSE-C）且对象没有完整的 sha256 校验和时，视为不一致
+	CompareChecksum CompareMode = "checksum"
+)
+
+type Options struct {
+	// optional, glob patterns of relative paths to sync, default to all files, see Exclude for syntax
+	Include []string
+
+	// optional, glob patterns of relative paths to skip, take precedence over Include,
+	// "*" matches any characters except "/", "**" matches any directories,
+	// patterns without "/" match file names in any directory
+	Exclude []string
+
+	// optional, default to CompareSizeModTime
+	Compare CompareMode
+
+	// optional, delete files in destination which do not exist in source,
+	// files filtered out by Include and Exclude are never deleted
+	Delete bool
+
+	// optional, plan actions without transferring or deleting any file
+	DryRun bool
+
+	// optional, number of files transferred concurrently, default to 4
+	Concurrency int
+
+	// optional, write each action before executing, or planned actions in dry run
+	Output io.Writer
+}
+
+func (o *Options) validate() error {
+	switch o.Compare {
+	case "", CompareSizeModTime, CompareSize, CompareChecksum:
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown compare mode: %s", s3common.ErrInvalidArgument, o.Compare)
+	}
+}
+
+func (o *Options) compare() CompareMode {
+	if o.Compare == "" {
+		return CompareSizeModTime
+	}
+	return o.Compare
+}
+
+func (o *Options) concurrency() int {
+	if o.Concurrency <= 0 {
+		return defaultConcurrency
+	}
+	return o.Concurrency
+}
+
+type ActionType string
+
+const (
+	ActionUpload   ActionType = "upload"
+	ActionDownload ActionType = "download"
+	ActionDelete   ActionType = "delete"
+)
+
+// Action 同步需要执行的操作
+type Action struct {
+	Type ActionType
+
+	// Path 相对于本地目录及远程前缀的路径，以 "/" 分隔
+	Path string
+
+	Size int64
+
+	// Reason 执行的原因，例如 new、size changed、modified、checksum changed、extraneous
+	Reason string
+}
+
+func (a Action) String() string {
+	return fmt.Sprintf("%s: %s (%s)", a.Type, a.Path, a.Reason)
+}
+
+type Result struct {
+	// Actions 需要执行的操作，DryRun 时不会执行
+	Actions []Action
+
+	// Unchanged 无需同步的文件数量
+	Unchanged int
+}
+
+// localFile 本地文件，Path 以 "/" 分隔
+type localFile struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+}
+
+// Upload 将本地目录同步到远程前缀，仅上传新增或变化的文件
+func Upload(ctx context.Context, storage s3.Storage, localDir string, prefix string, opts *Options) (*Result, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	m, err := newMatcher(opts.Include, opts.Exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	locals, err := walkLocal(localDir, m)
+	if err != nil {
+		return nil, err
+	}
+
+	remotes, err := walkRemote(ctx, storage, prefix, m)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &Result{}
+	for _, rel := range sortedKeys(locals) {
+		local := locals[rel]
+		remote, ok := remotes[rel]
+		if !ok {
+			ret.Actions = append(ret.Actions, Action{Type: ActionUpload, Path: rel, Size: local.Size, Reason: "new"})
+			continue
+		}
+
+		reason, err := compare(ctx, storage, opts.compare(), local, remote, filepath.Join(localDir, filepath.FromSlash(rel)), joinRemote(prefix, rel), true)
+		if err != nil {
+			return nil, err
+		}
+		if reason == "" {
+			ret.Unchanged++
+			continue
+		}
+		ret.Actions = append(ret.Actions, Action{Type: ActionUpload, Path: rel, Size: local.Size, Reason: reason})
+	}
+
+	var deletes []Action
+	if opts.Delete {
+		for _, rel := range sortedKeys(remotes) {
+			if _, ok := locals[rel]; !ok {
+				deletes = append(deletes, Action{Type: ActionDelete, Path: rel, Size: remotes[rel].Size, Reason: "extraneous"})
+			}
+		}
+	}
+
+	r := &reporter{w: opts.Output, dryRun: opts.DryRun}
+	err = execute(ctx, ret.Actions, opts, r, func(ctx context.Context, a Action) error {
+		return storage.UploadFile(ctx, joinRemote(prefix, a.Path), filepath.Join(localDir, filepath.FromSlash(a.Path)))
+	})
+	if err != nil {
+		return ret, err
+	}
+
+	ret.Actions = append(ret.Actions, deletes...)
+	if len(deletes) == 0 {
+		return ret, nil
+	}
+
+	paths := make([]string, 0, len(deletes))
+	for _, a := range deletes {
+		r.report(a)
+		paths = append(paths, joinRemote(prefix, a.Path))
+	}
+	if opts.DryRun {
+		return ret, nil
+	}
+
+	failed, err := storage.DeleteMany(ctx, paths)
+	if err != nil {
+		return ret, err
+	}
+	if len(failed) > 0 {
+		return ret, fmt.Errorf("failed to delete %s: %w", failed[0].Path, failed[0].Err)
+	}
+	return ret, nil
+}
+
+// Download 将远程前缀同步到本地目录，仅下载新增或变化的文件，下载后本地文件的修改时间设置为对象的修改时间
+func Download(ctx context.Context, storage s3.Storage, prefix string, localDir string, opts *Options) (*Result, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	m, err := newMatcher(opts.Include, opts.Exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	remotes, err := walkRemote(ctx, storage, prefix, m)
+	if err != nil {
+		return nil, err
+	}
+
+	locals, err := walkLocal(localDir, m)
+	if errors.Is(err, fs.ErrNotExist) {
+		locals = map[string]localFile{}
+	} else if err != nil {
+		return nil, err
+	}
+
+	ret := &Result{}
+	for _, rel := range sortedKeys(remotes) {
+		remote := remotes[rel]
+		local, ok := locals[rel]
+		if !ok {
+			ret.Actions = append(ret.Actions, Action{Type: ActionDownload, Path: rel, Size: remote.Size, Reason: "new"})
+			continue
+		}
+
+		reason, err := compare(ctx, storage, opts.compare(), local, remote, filepath.Join(localDir, filepath.FromSlash(rel)), joinRemote(prefix, rel), false)
+		if err != nil {
+			return nil, err
+		}
+		if reason == "" {
+			ret.Unchanged++
+			continue
+		}
+		ret.Actions = append(ret.Actions, Action{Type: ActionDownload, Path: rel, Size: remote.Size, Reason: reason})
+	}
+
+	var deletes []Action
+	if opts.Delete {
+		for _, rel := range sortedKeys(locals) {
+			if _, ok := remotes[rel]; !ok {
+				deletes = append(deletes, Action{Type: ActionDelete, Path: rel, Size: locals[rel].Size, Reason: "extraneous"})
+			}
+		}
+	}
+
+	r := &reporter{w: opts.Output, dryRun: opts.DryRun}
+	err = execute(ctx, ret.Actions, opts, r, func(ctx context.Context, a Action) error {
+		localPath := filepath.Join(localDir, filepath.FromSlash(a.Path))
+		if err := os.MkdirAll(filepath.Dir(localPath), 0o755); err != nil {
+			return err
+		}
+		if err := storage.DownloadFile(ctx, joinRemote(prefix, a.Path), localPath); err != nil {
+			return err
+		}
+		// keep mtime same as remote, so the file is considered unchanged in next sync
+		modTime := remotes[a.Path].LastModified
+		return os.Chtimes(localPath, modTime, modTime)
+	})
+	if err != nil {
+		return ret, err
+	}
+
+	ret.Actions = append(ret.Actions, deletes...)
+	return ret, execute(ctx, deletes, opts, r, func(_ context.Context, a Action) error {
+		return os.Remove(filepath.Join(localDir, filepath.FromSlash(a.Path)))
+	})
+}
+
+// execute 并发执行 actions，任一操作失败时取消其余操作并返回该错误
+func execute(ctx context.Context, actions []Action, opts *Options, r *reporter, fn func(ctx context.Context, a Action) error) error {
+	if opts.DryRun {
+		for _, a := range actions {
+			r.report(a)
+		}
+		return nil
+	}
+
+	pending := make(chan Action, len(actions))
+	for _, a := range actions {
+		pending <- a
+	}
+	close(pending)
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	var wg sync.WaitGroup
+	for range min(opts.concurrency(), len(actions)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for a := range pending {
+				if ctx.Err() != nil {
+					return
+				}
+				r.report(a)
+				if err := fn(ctx, a); err != nil {
+					cancel(fmt.Errorf("failed to %s %s: %w", a.Type, a.Path, err))
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	return context.Cause(ctx)
+}
+
+// reporter 将操作逐行写入 Options.Output，并发执行时保证每行完整
+type reporter struct {
+	mu     sync.Mutex
+	w      io.Writer
+	dryRun bool
+}
+
+func (r *reporter) report(a Action) {
+	if r.w == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.dryRun {
+		_, _ = fmt.Fprintf(r.w, "(dry run) %s\n", a)
+	} else {
+		_, _ = fmt.Fprintf(r.w, "%s\n", a)
+	}
+}
+
+// walkLocal 递归列举本地目录下匹配的普通文件，key 为以 "/" 分隔的相对路径
+func walkLocal(localDir string, m *matcher) (map[string]localFile, error) {
+	if _, err := os.Stat(localDir); err != nil {
+		return nil, err
+	}
+
+	ret := make(map[string]localFile)
+	err := filepath.WalkDir(localDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil // directories, symlinks and devices
+		}
+		if slices.ContainsFunc(tempSuffixes, func(suffix string) bool { return strings.HasSuffix(p, suffix) }) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(localDir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if !m.match(rel) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		ret[rel] = localFile{Path: rel, Size: info.Size(), ModTime: info.ModTime()}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// walkRemote 递归列举远程前缀下匹配的对象，key 为相对于前缀的路径，相对路径无法安全映射到本地目录时返回错误
+func walkRemote(ctx context.Context, storage s3.Storage, prefix string, m *matcher) (map[string]minio.ObjectInfo, error) {
+	listPrefix := strings.Trim(prefix, "/")
+	if listPrefix != "" {
+		listPrefix += "/"
+	}
+
+	ret := make(map[string]minio.ObjectInfo)
+	for obj, err := range storage.Walk(ctx, listPrefix) {
+		if err != nil {
+			return nil, err
+		}
+
+		rel := strings.TrimPrefix(strings.TrimPrefix(obj.Key, "/"), listPrefix)
+		if rel == "" || strings.HasSuffix(rel, "/") {
+			continue // directory marker
+		}
+		// reject keys like "../../.ssh/authorized_keys" which would be written outside local directory
+		if !filepath.IsLocal(filepath.FromSlash(rel)) {
+			return nil, fmt.Errorf("%w: object key is not a local path: %s", s3common.ErrInvalidArgument, obj.Key)
+		}
+		if !m.match(rel) {
+			continue
+		}
+		ret[rel] = obj
+	}
+	return ret, nil
+}
+
+func joinRemote(prefix string, rel string) string {
+	return path.Join(strings.Trim(prefix, "/"), rel)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,257 @@
+package s3sync_test
+
+import (
+	"bytes"
+	"context"
+	"iter"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ix64/s3-go/s3"
+	"github.com/ix64/s3-go/s3mem"
+	"github.com/ix64/s3-go/s3sync"
+)
+
+func writeFile(t *testing.T, dir, rel, content string, modTime time.Time) {
+	t.Helper()
+
+	p := filepath.Join(dir, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(p, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func actionPaths(ret *s3sync.Result, typ s3sync.ActionType) []string {
+	var paths []string
+	for _, a := range ret.Actions {
+		if a.Type == typ {
+			paths = append(paths, a.Path)
+		}
+	}
+	return paths
+}
+
+// multipartStorage 模拟分片上传的对象，ETag 不是 MD5 且仅有完整的 crc32c 校验和
+type multipartStorage struct {
+	s3.Storage
+}
+
+func (s *multipartStorage) multipart(info minio.ObjectInfo) minio.ObjectInfo {
+	info.ETag = "d41d8cd98f00b204e9800998ecf8427e-2"
+	info.ChecksumSHA256 = ""
+	return info
+}
+
+func (s *multipartStorage) Stat(ctx context.Context, remotePath string) (minio.ObjectInfo, error) {
+	info, err := s.Storage.Stat(ctx, remotePath)
+	return s.multipart(info), err
+}
+
+func (s *multipartStorage) Walk(ctx context.Context, prefix string) iter.Seq2[minio.ObjectInfo, error] {
+	return func(yield func(minio.ObjectInfo, error) bool) {
+		for obj, err := range s.Storage.Walk(ctx, prefix) {
+			if !yield(s.multipart(obj), err) {
+				return
+			}
+		}
+	}
+}
+
+// evilStorage 在列举结果中追加指向本地目录之外的 key
+type evilStorage struct {
+	s3.Storage
+	key string
+}
+
+func (s *evilStorage) Walk(ctx context.Context, prefix string) iter.Seq2[minio.ObjectInfo, error] {
+	return func(yield func(minio.ObjectInfo, error) bool) {
+		for obj, err := range s.Storage.Walk(ctx, prefix) {
+			if !yield(obj, err) {
+				return
+			}
+		}
+		yield(minio.ObjectInfo{Key: s.key, Size: 1, LastModified: time.Now()}, nil)
+	}
+}
+
+func TestUpload(t *testing.T) {
+	ctx := context.Background()
+	c, err := s3mem.NewClient(&s3mem.Config{Prefix: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	past := time.Now().Add(-time.Hour)
+	writeFile(t, dir, "index.html", "<html>", past)
+	writeFile(t, dir, "assets/app.js", "console.log(1)", past)
+	writeFile(t, dir, "assets/app.js.map", "{}", past)
+	writeFile(t, dir, "cache/tmp.bin", "tmp", past)
+
+	opts := &s3sync.Options{Exclude: []string{"*.map", "cache/"}}
+
+	t.Run("DryRun", func(t *testing.T) {
+		var out bytes.Buffer
+		ret, err := s3sync.Upload(ctx, c, dir, "site", &s3sync.Options{
+			Exclude: opts.Exclude,
+			DryRun:  true,
+			Output:  &out,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"assets/app.js", "index.html"}, actionPaths(ret, s3sync.ActionUpload))
+		assert.Contains(t, out.String(), "(dry run) upload: index.html (new)")
+
+		_, err = c.Stat(ctx, "site/index.html")
+		assert.ErrorIs(t, err, s3.ErrNotFound)
+	})
+
+	t.Run("Upload", func(t *testing.T) {
+		ret, err := s3sync.Upload(ctx, c, dir, "site", opts)
+		assert.NoError(t, err)
+		assert.Len(t, ret.Actions, 2)
+
+		info, err := c.Stat(ctx, "site/assets/app.js")
+		assert.NoError(t, err)
+		assert.Equal(t, int64(len("console.log(1)")), info.Size)
+
+		_, err = c.Stat(ctx, "site/assets/app.js.map")
+		assert.ErrorIs(t, err, s3.ErrNotFound)
+	})
+
+	t.Run("Unchanged", func(t *testing.T) {
+		ret, err := s3sync.Upload(ctx, c, dir, "site", opts)
+		assert.NoError(t, err)
+		assert.Empty(t, ret.Actions)
+		assert.Equal(t, 2, ret.Unchanged)
+	})
+
+	t.Run("Changed", func(t *testing.T) {
+		writeFile(t, dir, "index.html", "<html></html>", time.Now())
+
+		ret, err := s3sync.Upload(ctx, c, dir, "site", opts)
+		assert.NoError(t, err)
+		if assert.Len(t, ret.Actions, 1) {
+			assert.Equal(t, s3sync.Action{Type: s3sync.ActionUpload, Path: "index.html", Size: 13, Reason: "size changed"}, ret.Actions[0])
+		}
+	})
+
+	t.Run("Checksum", func(t *testing.T) {
+		// same size, older mtime, different content
+		writeFile(t, dir, "index.html", "<html>-----</html>"[:13], past)
+
+		ret, err := s3sync.Upload(ctx, c, dir, "site", opts)
+		assert.NoError(t, err)
+		assert.Empty(t, ret.Actions)
+
+		ret, err = s3sync.Upload(ctx, c, dir, "site", &s3sync.Options{Exclude: opts.Exclude, Compare: s3sync.CompareChecksum})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"index.html"}, actionPaths(ret, s3sync.ActionUpload))
+	})
+
+	t.Run("ChecksumMultipart", func(t *testing.T) {
+		storage := &multipartStorage{Storage: c}
+		checksumOpts := &s3sync.Options{Exclude: opts.Exclude, Compare: s3sync.CompareChecksum}
+
+		ret, err := s3sync.Upload(ctx, storage, dir, "site", checksumOpts)
+		assert.NoError(t, err)
+		assert.Empty(t, ret.Actions)
+
+		writeFile(t, dir, "index.html", "<html>+++++</html>"[:13], past)
+
+		ret, err = s3sync.Upload(ctx, storage, dir, "site", checksumOpts)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"index.html"}, actionPaths(ret, s3sync.ActionUpload))
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		assert.NoError(t, c.Upload(ctx, "site/stale.txt", strings.NewReader("stale"), 5, "text/plain"))
+		assert.NoError(t, os.Remove(filepath.Join(dir, "assets/app.js")))
+
+		ret, err := s3sync.Upload(ctx, c, dir, "site", &s3sync.Options{Exclude: opts.Exclude, Delete: true})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"assets/app.js", "stale.txt"}, actionPaths(ret, s3sync.ActionDelete))
+
+		_, err = c.Stat(ctx, "site/stale.txt")
+		assert.ErrorIs(t, err, s3.ErrNotFound)
+		_, err = c.Stat(ctx, "site/index.html")
+		assert.NoError(t, err)
+	})
+
+	t.Run("InvalidPattern", func(t *testing.T) {
+		_, err := s3sync.Upload(ctx, c, dir, "site", &s3sync.Options{Include: []string{"[abc"}})
+		assert.ErrorIs(t, err, s3.ErrInvalidArgument)
+	})
+}
+
+func TestDownload(t *testing.T) {
+	ctx := context.Background()
+	c, err := s3mem.NewClient(&s3mem.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, p := range []string{"backup/a.txt", "backup/dir/b.txt", "backup/dir/c.log", "backup2/other.txt"} {
+		assert.NoError(t, c.Upload(ctx, p, strings.NewReader(p), int64(len(p)), "text/plain"))
+	}
+
+	dir := filepath.Join(t.TempDir(), "restore")
+	opts := &s3sync.Options{Include: []string{"*.txt"}, Concurrency: 2}
+
+	t.Run("Download", func(t *testing.T) {
+		ret, err := s3sync.Download(ctx, c, "backup", dir, opts)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a.txt", "dir/b.txt"}, actionPaths(ret, s3sync.ActionDownload))
+
+		buf, err := os.ReadFile(filepath.Join(dir, "dir", "b.txt"))
+		assert.NoError(t, err)
+		assert.Equal(t, "backup/dir/b.txt", string(buf))
+
+		_, err = os.Stat(filepath.Join(dir, "dir", "c.log"))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("Unchanged", func(t *testing.T) {
+		ret, err := s3sync.Download(ctx, c, "backup", dir, opts)
+		assert.NoError(t, err)
+		assert.Empty(t, ret.Actions)
+		assert.Equal(t, 2, ret.Unchanged)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		writeFile(t, dir, "extra.txt", "extra", time.Now())
+		writeFile(t, dir, "keep.log", "excluded", time.Now())
+
+		ret, err := s3sync.Download(ctx, c, "backup", dir, &s3sync.Options{Include: opts.Include, Delete: true})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"extra.txt"}, actionPaths(ret, s3sync.ActionDelete))
+
+		_, err = os.Stat(filepath.Join(dir, "extra.txt"))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+		_, err = os.Stat(filepath.Join(dir, "keep.log"))
+		assert.NoError(t, err)
+	})
+
+	t.Run("PathTraversal", func(t *testing.T) {
+		for _, key := range []string{"backup/../../evil.txt", "backup//etc/evil.txt"} {
+			storage := &evilStorage{Storage: c, key: key}
+
+			_, err := s3sync.Download(ctx, storage, "backup", dir, &s3sync.Options{})
+			assert.ErrorIs(t, err, s3.ErrInvalidArgument, key)
+		}
+
+		_, err := os.Stat(filepath.Join(filepath.Dir(dir), "evil.txt"))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
@@ -3,8 +3,12 @@ package s3up
 import (
 	"path"
 	"strings"
+	"time"
 )
 
+// timeNow 在测试中替换为固定值，以校验固定的签名结果
+var timeNow = time.Now
+
 func composeObjectName(prefix string, remotePath string) string {
 	// s3 object name can not start with "/"
 	return strings.TrimPrefix(path.Join(prefix, remotePath), "/")
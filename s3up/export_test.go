@@ -0,0 +1,18 @@
+package s3up
+
+import (
+	"testing"
+	"time"
+)
+
+// QiniuSignPolicy 导出 qiniuSignPolicy，使用七牛文档中的示例校验签名
+var QiniuSignPolicy = qiniuSignPolicy
+
+// SetClock 固定签名使用的当前时间，测试结束时恢复
+func SetClock(t testing.TB, now time.Time) {
+	orig := timeNow
+	timeNow = func() time.Time { return now }
+	t.Cleanup(func() {
+		timeNow = orig
+	})
+}
@@ -0,0 +1,196 @@
+package s3up
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/minio/minio-go/v7/pkg/credentials"
+
+	"github.com/ix64/s3-go/s3common"
+)
+
+type GeneratorQiniuConfig struct {
+	// Endpoint 填写空间所在区域的上传域名，例如华东：https://up-z0.qiniup.com
+	// 参考: https://developer.qiniu.com/kodo/1671/region-endpoint-fq
+	Endpoint string `json:"endpoint"`
+	Bucket   string `json:"bucket"`
+	Prefix   string `json:"prefix"`
+
+	AccessKey string `json:"access_key"`
+	SecretKey string `json:"secret_key"`
+
+	// Creds is optional, take precedence over AccessKey and SecretKey, used to share credentials with client.
+	// 七牛上传凭证不支持 SessionToken，因此只能使用长期凭证
+	Creds *credentials.Credentials `json:"-"`
+
+	// MimeLimit optional, 允许上传的文件类型，例如：image/*;video/mp4，GenerateParams.ContentType 非空时以其为准
+	MimeLimit string `json:"mime_limit"`
+
+	// CallbackURL optional, 上传成功后七牛回调的地址，多个地址以 ";" 分隔
+	CallbackURL string `json:"callback_url"`
+
+	// CallbackHost optional, 回调请求的 Host
+	CallbackHost string `json:"callback_host"`
+
+	// CallbackBody optional, 回调请求的内容，支持魔法变量，例如：key=$(key)&fsize=$(fsize)&hash=$(etag)
+	CallbackBody string `json:"callback_body"`
+
+	// CallbackBodyType optional, 回调请求的 Content-Type，默认为 application/x-www-form-urlencoded
+	CallbackBodyType string `json:"callback_body_type"`
+
+	// DisableChecksum 七牛上传凭证不支持 sha256 校验，未开启时 GenerateParams.Sha256 非空会返回 ErrNotSupported
+	// 开启后忽略 Sha256，存在风险，即用户上传的文件 hash 值不会校验
+	DisableChecksum bool `json:"disable_checksum"`
+}
+
+func (c *GeneratorQiniuConfig) Validate() error {
+	if c.Endpoint == "" {
+		return &s3common.ConfigError{Field: "endpoint", Reason: "is required"}
+	}
+
+	if c.Bucket == "" {
+		return &s3common.ConfigError{Field: "bucket", Reason: "is required"}
+	}
+
+	if c.Creds == nil && (c.AccessKey == "" || c.SecretKey == "") {
+		return &s3common.ConfigError{Field: "access_key", Reason: "and secret_key is required"}
+	}
+
+	if c.CallbackURL != "" && c.CallbackBody == "" {
+		return &s3common.ConfigError{Field: "callback_body", Reason: "is required when callback_url is set"}
+	}
+
+	return nil
+}
+
+// GeneratorQiniu 使用七牛上传凭证生成表单直传参数，终端用户需在表单中追加 file 字段
+// 参考: https://developer.qiniu.com/kodo/1312/upload
+//
+// 七牛不支持上传时指定 Content-Disposition，因此忽略 AttachmentFilename，下载文件名可通过下载生成器的 attname 参数指定；
+// 七牛不支持 sha256 校验，Sha256 非空时返回 ErrNotSupported，除非开启 DisableChecksum
+type GeneratorQiniu struct {
+	endpoint *url.URL
+	cfg      *GeneratorQiniuConfig
+}
+
+func NewGeneratorQiniu(cfg *GeneratorQiniuConfig) (Generator, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, &s3common.ConfigError{Field: "endpoint", Reason: "is invalid: " + err.Error()}
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, &s3common.ConfigError{Field: "endpoint", Reason: "scheme must be http or https"}
+	}
+
+	return &GeneratorQiniu{
+		cfg:      cfg,
+		endpoint: u,
+	}, nil
+}
+
+// qiniuPutPolicy 七牛上传策略
+// 参考: https://developer.qiniu.com/kodo/1206/put-policy
+type qiniuPutPolicy struct {
+	Scope            string `json:"scope"`
+	Deadline         int64  `json:"deadline"`
+	FsizeMin         int64  `json:"fsizeMin"`
+	FsizeLimit       int64  `json:"fsizeLimit"`
+	MimeLimit        string `json:"mimeLimit,omitempty"`
+	CallbackURL      string `json:"callbackUrl,omitempty"`
+	CallbackHost     string `json:"callbackHost,omitempty"`
+	CallbackBody     string `json:"callbackBody,omitempty"`
+	CallbackBodyType string `json:"callbackBodyType,omitempty"`
+}
+
+func (p *GeneratorQiniu) GenerateUpload(ctx context.Context, params *GenerateParams) (*GenerateResult, error) {
+	// fsizeLimit of 0 rejects every upload
+	if params.Size <= 0 {
+		return nil, fmt.Errorf("%w: invalid size: %d", s3common.ErrInvalidArgument, params.Size)
+	}
+
+	if params.Sha256 != nil && !p.cfg.DisableChecksum {
+		return nil, fmt.Errorf("%w: qiniu upload token can not enforce sha256, set disable_checksum to ignore it", s3common.ErrNotSupported)
+	}
+
+	objectName := composeObjectName(p.cfg.Prefix, params.RemotePath)
+
+	policy := &qiniuPutPolicy{
+		// enforce object name, "bucket:key" allows overwriting the same key
+		Scope:    p.cfg.Bucket + ":" + objectName,
+		Deadline: timeNow().Add(params.ExpireIn).Unix(),
+
+		// enforce file size
+		FsizeMin:   params.Size,
+		FsizeLimit: params.Size,
+
+		MimeLimit: p.cfg.MimeLimit,
+
+		CallbackURL:      p.cfg.CallbackURL,
+		CallbackHost:     p.cfg.CallbackHost,
+		CallbackBody:     p.cfg.CallbackBody,
+		CallbackBodyType: p.cfg.CallbackBodyType,
+	}
+
+	// enforce content type, checked against content type of form file since detectMime is 0
+	if params.ContentType != "" {
+		policy.MimeLimit = params.ContentType
+	}
+
+	token, err := p.uploadToken(ctx, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	formData := map[string]string{
+		"token": token,
+		"key":   objectName,
+	}
+	for k, v := range params.Metadata {
+		formData["x-qn-meta-"+k] = v
+	}
+
+	u := *p.endpoint // copy
+	return &GenerateResult{
+		Method:   http.MethodPost,
+		URL:      &u,
+		FormData: formData,
+	}, nil
+}
+
+// uploadToken 上传凭证为 AccessKey:base64(hmac-sha1(encodedPolicy)):encodedPolicy
+// 参考: https://developer.qiniu.com/kodo/1208/upload-token
+func (p *GeneratorQiniu) uploadToken(ctx context.Context, policy *qiniuPutPolicy) (string, error) {
+	accessKey, secretKey, err := s3common.LongTermKeys(ctx, p.cfg.Creds, p.cfg.AccessKey, p.cfg.SecretKey)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(policy)
+	if err != nil {
+		return "", err
+	}
+
+	return qiniuSignPolicy(accessKey, secretKey, data), nil
+}
+
+// qiniuSignPolicy 对上传策略 JSON 签名，返回上传凭证
+func qiniuSignPolicy(accessKey, secretKey string, policy []byte) string {
+	encodedPolicy := base64.URLEncoding.EncodeToString(policy)
+
+	mac := hmac.New(sha1.New, []byte(secretKey))
+	mac.Write([]byte(encodedPolicy))
+	sign := base64.URLEncoding.EncodeToString(mac.Sum(nil))
+
+	return accessKey + ":" + sign + ":" + encodedPolicy
+}
@@ -0,0 +1,116 @@
+package s3up_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ix64/s3-go/s3common"
+	"github.com/ix64/s3-go/s3up"
+)
+
+// TestQiniuSignPolicy 使用七牛上传凭证文档中的示例
+// 参考: https://developer.qiniu.com/kodo/1208/upload-token
+func TestQiniuSignPolicy(t *testing.T) {
+	policy := `{"scope":"my-bucket:sunflower.jpg","deadline":1451491200,"returnBody":"{\"name\":$(fname),\"size\":$(fsize),\"w\":$(imageInfo.width),\"h\":$(imageInfo.height),\"hash\":$(etag)}"}`
+
+	assert.Equal(t,
+		"MY_ACCESS_KEY:wQ4ofysef1R7IKnrziqtomqyDvI=:eyJzY29wZSI6Im15LWJ1Y2tldDpzdW5mbG93ZXIuanBnIiwiZGVhZGxpbmUiOjE0NTE0OTEyMDAsInJldHVybkJvZHkiOiJ7XCJuYW1lXCI6JChmbmFtZSksXCJzaXplXCI6JChmc2l6ZSksXCJ3XCI6JChpbWFnZUluZm8ud2lkdGgpLFwiaFwiOiQoaW1hZ2VJbmZvLmhlaWdodCksXCJoYXNoXCI6JChldGFnKX0ifQ==",
+		s3up.QiniuSignPolicy("MY_ACCESS_KEY", "MY_SECRET_KEY", []byte(policy)),
+	)
+}
+
+func TestGeneratorQiniu(t *testing.T) {
+	s3up.SetClock(t, time.Unix(1700000000, 0))
+
+	g, err := s3up.NewGeneratorQiniu(&s3up.GeneratorQiniuConfig{
+		Endpoint:     "https://up-z0.qiniup.com",
+		Bucket:       "my-bucket",
+		Prefix:       "app",
+		AccessKey:    "ak",
+		SecretKey:    "sk",
+		MimeLimit:    "image/*",
+		CallbackURL:  "https://api.example.com/callback",
+		CallbackBody: "key=$(key)",
+	})
+	require.NoError(t, err)
+
+	ret, err := g.GenerateUpload(context.Background(), &s3up.GenerateParams{
+		RemotePath: "hello.txt",
+		ExpireIn:   time.Minute,
+		Size:       5,
+		Metadata:   map[string]string{"owner": "alice"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, http.MethodPost, ret.Method)
+	assert.Equal(t, "https://up-z0.qiniup.com", ret.URL.String())
+	assert.Equal(t, "app/hello.txt", ret.FormData["key"])
+	assert.Equal(t, "alice", ret.FormData["x-qn-meta-owner"])
+
+	// policy: {"scope":"my-bucket:app/hello.txt","deadline":1700000060,"fsizeMin":5,"fsizeLimit":5,"mimeLimit":"image/*","callbackUrl":"https://api.example.com/callback","callbackBody":"key=$(key)"}
+	assert.Equal(t, "ak:uMCIS4EW9QPX9UXZ65XlTt5y-UE=:eyJzY29wZSI6Im15LWJ1Y2tldDphcHAvaGVsbG8udHh0IiwiZGVhZGxpbmUiOjE3MDAwMDAwNjAsImZzaXplTWluIjo1LCJmc2l6ZUxpbWl0Ijo1LCJtaW1lTGltaXQiOiJpbWFnZS8qIiwiY2FsbGJhY2tVcmwiOiJodHRwczovL2FwaS5leGFtcGxlLmNvbS9jYWxsYmFjayIsImNhbGxiYWNrQm9keSI6ImtleT0kKGtleSkifQ==", ret.FormData["token"])
+}
+
+func TestGeneratorQiniuCreds(t *testing.T) {
+	newGenerator := func(creds *credentials.Credentials) s3up.Generator {
+		g, err := s3up.NewGeneratorQiniu(&s3up.GeneratorQiniuConfig{
+			Endpoint: "https://up-z0.qiniup.com",
+			Bucket:   "my-bucket",
+			Creds:    creds,
+		})
+		require.NoError(t, err)
+		return g
+	}
+	params := &s3up.GenerateParams{RemotePath: "hello.txt", ExpireIn: time.Minute, Size: 5}
+
+	t.Run("Static", func(t *testing.T) {
+		ret, err := newGenerator(credentials.NewStaticV4("ak", "sk", "")).GenerateUpload(context.Background(), params)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(ret.FormData["token"], "ak:"))
+	})
+
+	t.Run("SessionToken", func(t *testing.T) {
+		_, err := newGenerator(credentials.NewStaticV4("ak", "sk", "token")).GenerateUpload(context.Background(), params)
+		assert.ErrorIs(t, err, s3common.ErrInvalidCredentials)
+	})
+}
+
+func TestGeneratorQiniuInvalid(t *testing.T) {
+	newGenerator := func(disableChecksum bool) s3up.Generator {
+		g, err := s3up.NewGeneratorQiniu(&s3up.GeneratorQiniuConfig{
+			Endpoint:        "https://up-z0.qiniup.com",
+			Bucket:          "my-bucket",
+			AccessKey:       "ak",
+			SecretKey:       "sk",
+			DisableChecksum: disableChecksum,
+		})
+		require.NoError(t, err)
+		return g
+	}
+
+	ctx := context.Background()
+	sum := sha256.Sum256([]byte("hello"))
+
+	t.Run("NoSize", func(t *testing.T) {
+		_, err := newGenerator(false).GenerateUpload(ctx, &s3up.GenerateParams{RemotePath: "hello.txt", ExpireIn: time.Minute})
+		assert.ErrorIs(t, err, s3common.ErrInvalidArgument)
+	})
+
+	t.Run("Sha256", func(t *testing.T) {
+		params := &s3up.GenerateParams{RemotePath: "hello.txt", ExpireIn: time.Minute, Size: 5, Sha256: sum[:]}
+
+		_, err := newGenerator(false).GenerateUpload(ctx, params)
+		assert.ErrorIs(t, err, s3common.ErrNotSupported)
+
+		_, err = newGenerator(true).GenerateUpload(ctx, params)
+		assert.NoError(t, err)
+	})
+}
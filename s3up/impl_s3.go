@@ -2,15 +2,17 @@ package s3up
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
 
 	"github.com/ix64/s3-go/s3common"
 )
@@ -26,6 +28,19 @@ type GeneratorS3Config struct {
 	AccessKey string `json:"access_key"`
 	SecretKey string `json:"secret_key"`
 
+	// Credentials is optional, take precedence over AccessKey and SecretKey
+	Credentials *s3common.CredentialsConfig `json:"credentials"`
+
+	// Creds is optional, take precedence over Credentials, used to share refreshing credentials
+	Creds *credentials.Credentials `json:"-"`
+
+	// Encryption is optional, enforce server-side encryption of uploaded objects, sse-c is not supported
+	Encryption *s3common.EncryptionConfig `json:"encryption"`
+
+	// Retention is optional, enforce Object Lock retention or legal hold of uploaded objects,
+	// sha256 checksum of uploaded file is required since Object Lock requires Content-MD5 or checksum
+	Retention *s3common.RetentionConfig `json:"retention"`
+
 	// DisableChecksum 部分供应商不支持 sha256 校验
 	// 关闭后存在风险，即用户上传的文件 hash 值不会校验
 	DisableChecksum bool `json:"disable_checksum"`
@@ -39,27 +54,60 @@ type GeneratorS3Config struct {
 type GeneratorS3 struct {
 	client *minio.Client
 	cfg    *GeneratorS3Config
+
+	// bucket 为传给 SDK 的 bucket 名称，cname 时为自定义域名拆分出的虚拟主机前缀
+	bucket string
+
+	// cname 为自定义域名，非 nil 时 POST 表单提交至该地址
+	cname *url.URL
+
+	// sse 写入预签名链接的服务端加密方式
+	sse encrypt.ServerSide
 }
 
 func (c *GeneratorS3Config) Validate() error {
 	if c.Endpoint == "" {
-		return errors.New("endpoint is required")
+		return &s3common.ConfigError{Field: "endpoint", Reason: "is required"}
 	}
 
 	if c.Bucket == "" {
-		return errors.New("bucket is required")
+		return &s3common.ConfigError{Field: "bucket", Reason: "is required"}
 	}
 
 	if c.Region == "" {
-		return errors.New("region is required")
+		return &s3common.ConfigError{Field: "region", Reason: "is required"}
 	}
 
-	if c.AccessKey == "" || c.SecretKey == "" {
-		return errors.New("access key and secret key is required when anonymous is false")
+	if c.Creds == nil && c.Credentials == nil && (c.AccessKey == "" || c.SecretKey == "") {
+		return &s3common.ConfigError{Field: "access_key", Reason: "and secret_key is required"}
+	}
+
+	if c.Credentials != nil {
+		if err := c.Credentials.Validate(); err != nil {
+			return err
+		}
 	}
 
 	if c.BucketLookup == "" {
-		return errors.New("bucket lookup is required")
+		return &s3common.ConfigError{Field: "bucket_lookup", Reason: "is required"}
+	}
+
+	if c.Retention != nil {
+		if err := c.Retention.Validate(); err != nil {
+			return err
+		}
+		if c.DisableChecksum {
+			return &s3common.ConfigError{Field: "retention", Reason: "can not be used with disable_checksum"}
+		}
+	}
+
+	if c.Encryption != nil {
+		if err := c.Encryption.Validate(); err != nil {
+			return err
+		}
+		if c.Encryption.Type == s3common.EncryptionTypeC {
+			return &s3common.ConfigError{Field: "encryption.type", Reason: "sse-c is not supported, customer key would be exposed to uploader"}
+		}
 	}
 
 	return nil
@@ -72,9 +120,19 @@ func NewGeneratorS3(cfg *GeneratorS3Config) (Generator, error) {
 
 	u, err := url.Parse(cfg.Endpoint)
 	if err != nil {
-		return nil, err
+		return nil, &s3common.ConfigError{Field: "endpoint", Reason: "is invalid: " + err.Error()}
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, &s3common.ConfigError{Field: "endpoint", Reason: "scheme must be http or https"}
 	}
 
+	g := &GeneratorS3{
+		cfg:    cfg,
+		bucket: cfg.Bucket,
+	}
+	host := u.Host
+
 	var bucketLookup minio.BucketLookupType
 	switch cfg.BucketLookup {
 	case s3common.BucketLookupDNS:
@@ -82,13 +140,29 @@ func NewGeneratorS3(cfg *GeneratorS3Config) (Generator, error) {
 	case s3common.BucketLookupPath:
 		bucketLookup = minio.BucketLookupPath
 	case s3common.BucketLookupCNAME:
-		return nil, errors.New("custom domain by CNAME is not supported for S3 UploadGenerator")
+		// bucket is implicit, access custom domain as virtual host
+		g.bucket, host, err = s3common.SplitCNAMEHost(host)
+		if err != nil {
+			return nil, err
+		}
+		g.cname = &url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/"}
+		bucketLookup = minio.BucketLookupDNS
 	default:
-		return nil, fmt.Errorf("unknown bucket lookup type: %s", cfg.BucketLookup)
+		return nil, &s3common.ConfigError{Field: "bucket_lookup", Reason: "is unknown: " + string(cfg.BucketLookup)}
+	}
+
+	g.sse, err = s3common.NewServerSide(cfg.Encryption)
+	if err != nil {
+		return nil, err
 	}
 
-	client, err := minio.New(u.Host, &minio.Options{
-		Creds:        credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+	creds, err := s3common.ResolveCredentials(cfg.Creds, cfg.Credentials, cfg.AccessKey, cfg.SecretKey)
+	if err != nil {
+		return nil, err
+	}
+
+	g.client, err = minio.New(host, &minio.Options{
+		Creds:        creds,
 		Secure:       u.Scheme == "https",
 		BucketLookup: bucketLookup,
 		Region:       cfg.Region,
@@ -97,13 +171,51 @@ func NewGeneratorS3(cfg *GeneratorS3Config) (Generator, error) {
 		return nil, err
 	}
 
-	return &GeneratorS3{
-		cfg:    cfg,
-		client: client,
-	}, nil
+	return g, nil
+}
+
+var _ CORSGenerator = (*GeneratorS3)(nil)
+
+// CORSRequirement 返回浏览器使用 GenerateUpload 及 GenerateMultipartUpload 结果直传所需的 CORS 配置
+//
+// POST 表单请求无需额外的 header，PUT 请求需要允许链接中签名的 header，
+// 分片上传需要读取 ETag 响应头
+func (p *GeneratorS3) CORSRequirement() *CORSRequirement {
+	ret := &CORSRequirement{
+		Methods:        []string{http.MethodPut},
+		AllowedHeaders: []string{strings.ToLower(headerContentType), headerUserMetadataPrefix + "*"},
+		ExposeHeaders:  []string{"etag", "x-amz-version-id"},
+	}
+	if !p.cfg.DisablePOST {
+		ret.Methods = append(ret.Methods, http.MethodPost)
+	}
+
+	// collect signed headers the same way as generatePUT
+	header := http.Header{}
+	if !p.cfg.DisableChecksum {
+		header.Set(headerAmzChecksumAlgorithm, "")
+		header.Set(minio.ChecksumSHA256.Key(), "")
+		ret.ExposeHeaders = append(ret.ExposeHeaders, strings.ToLower(minio.ChecksumSHA256.Key()))
+	}
+	if p.sse != nil {
+		p.sse.Marshal(header)
+	}
+	if p.cfg.Retention != nil {
+		p.cfg.Retention.Marshal(header, time.Now())
+	}
+	for k := range header {
+		ret.AllowedHeaders = append(ret.AllowedHeaders, strings.ToLower(k))
+	}
+	slices.Sort(ret.AllowedHeaders)
+
+	return ret
 }
 
 func (p *GeneratorS3) GenerateUpload(ctx context.Context, params *GenerateParams) (*GenerateResult, error) {
+	if p.cfg.Retention != nil && params.Sha256 == nil {
+		return nil, fmt.Errorf("%w: sha256 is required when retention is enforced", s3common.ErrInvalidArgument)
+	}
+
 	if p.cfg.DisablePOST {
 		return p.generatePUT(ctx, params)
 	}
@@ -113,7 +225,7 @@ func (p *GeneratorS3) GenerateUpload(ctx context.Context, params *GenerateParams
 func (p *GeneratorS3) generatePOST(ctx context.Context, params *GenerateParams) (*GenerateResult, error) {
 	policy := minio.NewPostPolicy()
 
-	// enforce bucket name
+	// enforce bucket name, policy condition is checked by server with the real bucket
 	if err := policy.SetBucket(p.cfg.Bucket); err != nil {
 		return nil, err
 	}
@@ -167,9 +279,30 @@ func (p *GeneratorS3) generatePOST(ctx context.Context, params *GenerateParams)
 		}
 	}
 
+	// enforce server-side encryption and retention
+	// policy.SetEncryption sets form data without condition, so set them as conditions
+	header := http.Header{}
+	if p.sse != nil {
+		p.sse.Marshal(header)
+	}
+	if p.cfg.Retention != nil {
+		p.cfg.Retention.Marshal(header, time.Now())
+	}
+	for k := range header {
+		name := strings.TrimPrefix(strings.ToLower(k), "x-amz-")
+		if err := policy.SetUserData(name, header.Get(k)); err != nil {
+			return nil, err
+		}
+	}
+
 	u, formData, err := p.client.PresignedPostPolicy(ctx, policy)
 	if err != nil {
-		return nil, err
+		return nil, s3common.ConvertError(err)
+	}
+
+	// POST signature covers policy only, submit form to custom domain
+	if p.cname != nil {
+		u = p.cname
 	}
 
 	return &GenerateResult{
@@ -212,16 +345,26 @@ func (p *GeneratorS3) generatePUT(ctx context.Context, params *GenerateParams) (
 		}
 	}
 
+	// enforce server-side encryption
+	if p.sse != nil {
+		p.sse.Marshal(header)
+	}
+
+	// enforce retention
+	if p.cfg.Retention != nil {
+		p.cfg.Retention.Marshal(header, time.Now())
+	}
+
 	u, err := p.client.PresignHeader(ctx,
 		http.MethodPut,
-		p.cfg.Bucket,
+		p.bucket,
 		composeObjectName(p.cfg.Prefix, params.RemotePath),
 		params.ExpireIn,
 		nil,
 		header,
 	)
 	if err != nil {
-		return nil, err
+		return nil, s3common.ConvertError(err)
 	}
 
 	return &GenerateResult{
@@ -0,0 +1,244 @@
+package s3up
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+
+	"github.com/ix64/s3-go/s3common"
+)
+
+const (
+	multipartMinPartSize     = 5 << 20  // 5 MiB, S3 limit except the last part
+	multipartDefaultPartSize = 16 << 20 // 16 MiB
+	multipartMaxParts        = 10000    // S3 limit
+	multipartMaxSize         = 5 << 40  // 5 TiB, S3 limit
+)
+
+var _ MultipartGenerator = (*GeneratorS3)(nil)
+
+func (p *GeneratorS3) GenerateMultipartUpload(ctx context.Context, params *MultipartGenerateParams) (*MultipartGenerateResult, error) {
+	if params.Size <= 0 || params.Size > multipartMaxSize {
+		return nil, fmt.Errorf("%w: invalid size: %d", s3common.ErrInvalidArgument, params.Size)
+	}
+
+	partSize, err := multipartPartSize(params)
+	if err != nil {
+		return nil, err
+	}
+
+	partCount := int((params.Size + partSize - 1) / partSize)
+
+	withChecksum := !p.cfg.DisableChecksum && params.PartSha256 != nil
+	if withChecksum && len(params.PartSha256) != partCount {
+		return nil, fmt.Errorf("%w: part sha256 count mismatch: expect %d, got %d", s3common.ErrInvalidArgument, partCount, len(params.PartSha256))
+	}
+	if p.cfg.Retention != nil && !withChecksum {
+		return nil, fmt.Errorf("%w: part sha256 is required when retention is enforced", s3common.ErrInvalidArgument)
+	}
+
+	opts := minio.PutObjectOptions{
+		ContentType:          params.ContentType,
+		UserMetadata:         make(map[string]string, len(params.Metadata)+1),
+		ServerSideEncryption: p.sse,
+	}
+
+	if params.AttachmentFilename != "" {
+		opts.ContentDisposition = s3common.ComposeContentDisposition(params.AttachmentFilename)
+	}
+
+	for k, v := range params.Metadata {
+		opts.UserMetadata[headerUserMetadataPrefix+k] = v
+	}
+
+	// parts with checksum can only be uploaded if algorithm is declared on initiating
+	if withChecksum {
+		opts.UserMetadata[headerAmzChecksumAlgorithm] = minio.ChecksumSHA256.String()
+	}
+
+	if r := p.cfg.Retention; r != nil {
+		if r.Mode != "" {
+			opts.Mode = minio.RetentionMode(r.Mode)
+			opts.RetainUntilDate = r.RetainUntil(time.Now())
+		}
+		if r.LegalHold {
+			opts.LegalHold = minio.LegalHoldEnabled
+		}
+	}
+
+	objectName := composeObjectName(p.cfg.Prefix, params.RemotePath)
+
+	core := minio.Core{Client: p.client}
+	uploadID, err := core.NewMultipartUpload(ctx, p.bucket, objectName, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initiate multipart upload: %w", s3common.ConvertError(err))
+	}
+
+	ret := &MultipartGenerateResult{
+		UploadID: uploadID,
+		PartSize: partSize,
+		Parts:    make([]MultipartPartResult, 0, partCount),
+	}
+
+	for i := range partCount {
+		partNumber := i + 1
+		size := min(partSize, params.Size-int64(i)*partSize)
+
+		header := http.Header{}
+
+		// enforce part size
+		header.Set("Content-Length", strconv.FormatInt(size, 10))
+
+		// optionally enforce part sha256 checksum
+		if withChecksum {
+			checksum := minio.NewChecksum(minio.ChecksumSHA256, params.PartSha256[i])
+			header.Set(checksum.Type.Key(), checksum.Encoded())
+		}
+
+		query := url.Values{}
+		query.Set("partNumber", strconv.Itoa(partNumber))
+		query.Set("uploadId", uploadID)
+
+		u, err := p.client.PresignHeader(ctx,
+			http.MethodPut,
+			p.bucket,
+			objectName,
+			params.ExpireIn,
+			query,
+			header,
+		)
+		if err != nil {
+			return nil, s3common.ConvertError(err)
+		}
+
+		ret.Parts = append(ret.Parts, MultipartPartResult{
+			PartNumber: partNumber,
+			Size:       size,
+			Method:     http.MethodPut,
+			URL:        u,
+			Header:     header,
+		})
+	}
+
+	return ret, nil
+}
+
+func (p *GeneratorS3) CompleteMultipartUpload(ctx context.Context, params *MultipartCompleteParams) error {
+	if params.UploadID == "" {
+		return fmt.Errorf("%w: upload id is required", s3common.ErrInvalidArgument)
+	}
+
+	if len(params.Parts) == 0 {
+		return fmt.Errorf("%w: parts is required", s3common.ErrInvalidArgument)
+	}
+
+	objectName := composeObjectName(p.cfg.Prefix, params.RemotePath)
+
+	core := minio.Core{Client: p.client}
+
+	uploaded := make(map[int]minio.ObjectPart)
+	partNumberMarker := 0
+	for {
+		resp, err := core.ListObjectParts(ctx, p.bucket, objectName, params.UploadID, partNumberMarker, 0)
+		if err != nil {
+			return fmt.Errorf("failed to list uploaded parts: %w", s3common.ConvertError(err))
+		}
+
+		for _, part := range resp.ObjectParts {
+			uploaded[part.PartNumber] = part
+		}
+
+		if !resp.IsTruncated {
+			break
+		}
+		partNumberMarker = resp.NextPartNumberMarker
+	}
+
+	if len(uploaded) != len(params.Parts) {
+		return fmt.Errorf("%w: part count mismatch: uploaded %d, reported %d", s3common.ErrInvalidArgument, len(uploaded), len(params.Parts))
+	}
+
+	var totalSize int64
+	completeParts := make([]minio.CompletePart, 0, len(params.Parts))
+
+	for i, reported := range params.Parts {
+		// parts must be reported in ascending order without gaps
+		if reported.PartNumber != i+1 {
+			return fmt.Errorf("%w: unexpected part number: expect %d, got %d", s3common.ErrInvalidArgument, i+1, reported.PartNumber)
+		}
+
+		part, ok := uploaded[reported.PartNumber]
+		if !ok {
+			return fmt.Errorf("%w: part %d is not uploaded", s3common.ErrInvalidArgument, reported.PartNumber)
+		}
+
+		if !strings.EqualFold(trimETag(part.ETag), trimETag(reported.ETag)) {
+			return fmt.Errorf("%w: part %d etag mismatch", s3common.ErrChecksumMismatch, reported.PartNumber)
+		}
+
+		// checksum is enforced if it was declared on initiating
+		if !p.cfg.DisableChecksum && part.ChecksumSHA256 != "" {
+			if part.ChecksumSHA256 != base64.StdEncoding.EncodeToString(reported.Sha256) {
+				return fmt.Errorf("%w: part %d sha256 checksum mismatch", s3common.ErrChecksumMismatch, reported.PartNumber)
+			}
+		}
+
+		totalSize += part.Size
+		completeParts = append(completeParts, minio.CompletePart{
+			PartNumber:     part.PartNumber,
+			ETag:           part.ETag,
+			ChecksumSHA256: part.ChecksumSHA256,
+		})
+	}
+
+	if totalSize != params.Size {
+		return fmt.Errorf("%w: size mismatch: expect %d, uploaded %d", s3common.ErrInvalidArgument, params.Size, totalSize)
+	}
+
+	if _, err := core.CompleteMultipartUpload(ctx, p.bucket, objectName, params.UploadID, completeParts, minio.PutObjectOptions{}); err != nil {
+		return fmt.Errorf("failed to complete multipart upload: %w", s3common.ConvertError(err))
+	}
+
+	return nil
+}
+
+func (p *GeneratorS3) AbortMultipartUpload(ctx context.Context, remotePath string, uploadID string) error {
+	core := minio.Core{Client: p.client}
+	err := core.AbortMultipartUpload(ctx, p.bucket, composeObjectName(p.cfg.Prefix, remotePath), uploadID)
+	return s3common.ConvertError(err)
+}
+
+func multipartPartSize(params *MultipartGenerateParams) (int64, error) {
+	partSize := params.PartSize
+	if partSize == 0 {
+		if params.PartSha256 != nil {
+			return 0, fmt.Errorf("%w: part size is required when part sha256 is set", s3common.ErrInvalidArgument)
+		}
+
+		partSize = multipartDefaultPartSize
+		if minPartSize := (params.Size + multipartMaxParts - 1) / multipartMaxParts; partSize < minPartSize {
+			partSize = minPartSize
+		}
+	}
+
+	if partSize < multipartMinPartSize {
+		return 0, fmt.Errorf("%w: part size should be at least %d", s3common.ErrInvalidArgument, multipartMinPartSize)
+	}
+
+	if (params.Size+partSize-1)/partSize > multipartMaxParts {
+		return 0, fmt.Errorf("%w: too many parts, part size should be at least %d", s3common.ErrInvalidArgument, (params.Size+multipartMaxParts-1)/multipartMaxParts)
+	}
+
+	return partSize, nil
+}
+
+func trimETag(etag string) string {
+	return strings.Trim(etag, "\"")
+}
@@ -0,0 +1,226 @@
+package s3up_test
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ix64/s3-go/s3common"
+	"github.com/ix64/s3-go/s3up"
+)
+
+// newGeneratorS3 使用测试默认值补全 cfg 中未设置的连接参数后创建 S3 上传生成器
+func newGeneratorS3(t *testing.T, cfg *s3up.GeneratorS3Config) s3up.Generator {
+	t.Helper()
+
+	cfg.Endpoint = cmp.Or(cfg.Endpoint, "https://s3.example.com")
+	cfg.Bucket = cmp.Or(cfg.Bucket, "my-bucket")
+	cfg.BucketLookup = cmp.Or(cfg.BucketLookup, s3common.BucketLookupPath)
+	cfg.Region = cmp.Or(cfg.Region, "us-east-1")
+	cfg.AccessKey = cmp.Or(cfg.AccessKey, "ak")
+	cfg.SecretKey = cmp.Or(cfg.SecretKey, "sk")
+
+	g, err := s3up.NewGeneratorS3(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestGeneratorS3CNAME(t *testing.T) {
+	ctx := context.Background()
+
+	newGenerator := func(disablePOST bool) s3up.Generator {
+		return newGeneratorS3(t, &s3up.GeneratorS3Config{
+			BucketLookup: s3common.BucketLookupCNAME,
+			Prefix:       "app",
+			DisablePOST:  disablePOST,
+		})
+	}
+
+	params := &s3up.GenerateParams{
+		RemotePath: "hello.txt",
+		ExpireIn:   time.Minute,
+		Size:       5,
+	}
+
+	t.Run("PUT", func(t *testing.T) {
+		ret, err := newGenerator(true).GenerateUpload(ctx, params)
+		assert.NoError(t, err)
+		assert.Equal(t, http.MethodPut, ret.Method)
+		assert.Equal(t, "s3.example.com", ret.URL.Host)
+		assert.Equal(t, "/app/hello.txt", ret.URL.Path)
+		assert.NotEmpty(t, ret.URL.Query().Get("X-Amz-Signature"))
+	})
+
+	t.Run("POST", func(t *testing.T) {
+		ret, err := newGenerator(false).GenerateUpload(ctx, params)
+		assert.NoError(t, err)
+		assert.Equal(t, http.MethodPost, ret.Method)
+		assert.Equal(t, "https://s3.example.com/", ret.URL.String())
+		assert.Equal(t, "my-bucket", ret.FormData["bucket"])
+		assert.Equal(t, "app/hello.txt", ret.FormData["key"])
+	})
+
+	t.Run("InvalidEndpoint", func(t *testing.T) {
+		_, err := s3up.NewGeneratorS3(&s3up.GeneratorS3Config{
+			Endpoint:     "https://127.0.0.1:9000",
+			Bucket:       "my-bucket",
+			BucketLookup: s3common.BucketLookupCNAME,
+			Region:       "us-east-1",
+			AccessKey:    "ak",
+			SecretKey:    "sk",
+		})
+		assert.ErrorIs(t, err, s3common.ErrInvalidConfig)
+	})
+}
+
+func TestGeneratorS3Encryption(t *testing.T) {
+	ctx := context.Background()
+
+	newGenerator := func(disablePOST bool) s3up.Generator {
+		return newGeneratorS3(t, &s3up.GeneratorS3Config{
+			Encryption: &s3common.EncryptionConfig{
+				Type:     s3common.EncryptionTypeKMS,
+				KMSKeyID: "my-key",
+			},
+			DisablePOST: disablePOST,
+		})
+	}
+
+	params := &s3up.GenerateParams{
+		RemotePath: "hello.txt",
+		ExpireIn:   time.Minute,
+		Size:       5,
+	}
+
+	t.Run("PUT", func(t *testing.T) {
+		ret, err := newGenerator(true).GenerateUpload(ctx, params)
+		assert.NoError(t, err)
+		assert.Equal(t, "aws:kms", ret.Header.Get("X-Amz-Server-Side-Encryption"))
+		assert.Equal(t, "my-key", ret.Header.Get("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id"))
+		assert.Contains(t, ret.URL.Query().Get("X-Amz-SignedHeaders"), "x-amz-server-side-encryption")
+	})
+
+	t.Run("POST", func(t *testing.T) {
+		ret, err := newGenerator(false).GenerateUpload(ctx, params)
+		assert.NoError(t, err)
+		assert.Equal(t, "aws:kms", ret.FormData["x-amz-server-side-encryption"])
+		assert.Equal(t, "my-key", ret.FormData["x-amz-server-side-encryption-aws-kms-key-id"])
+
+		policy, err := base64.StdEncoding.DecodeString(ret.FormData["policy"])
+		assert.NoError(t, err)
+		assert.Contains(t, string(policy), `"$x-amz-server-side-encryption","aws:kms"`)
+	})
+
+	t.Run("SSEC", func(t *testing.T) {
+		_, err := s3up.NewGeneratorS3(&s3up.GeneratorS3Config{
+			Endpoint:     "https://s3.example.com",
+			Bucket:       "my-bucket",
+			BucketLookup: s3common.BucketLookupPath,
+			Region:       "us-east-1",
+			AccessKey:    "ak",
+			SecretKey:    "sk",
+			Encryption: &s3common.EncryptionConfig{
+				Type:        s3common.EncryptionTypeC,
+				CustomerKey: base64.StdEncoding.EncodeToString(make([]byte, 32)),
+			},
+		})
+		assert.ErrorIs(t, err, s3common.ErrInvalidConfig)
+	})
+}
+
+func TestGeneratorS3Retention(t *testing.T) {
+	ctx := context.Background()
+
+	newGenerator := func(disablePOST bool) s3up.Generator {
+		return newGeneratorS3(t, &s3up.GeneratorS3Config{
+			Retention: &s3common.RetentionConfig{
+				Mode:      s3common.RetentionModeCompliance,
+				Days:      30,
+				LegalHold: true,
+			},
+			DisablePOST: disablePOST,
+		})
+	}
+
+	sum := sha256.Sum256([]byte("hello"))
+	params := &s3up.GenerateParams{
+		RemotePath: "audit.log",
+		ExpireIn:   time.Minute,
+		Size:       5,
+		Sha256:     sum[:],
+	}
+
+	t.Run("PUT", func(t *testing.T) {
+		ret, err := newGenerator(true).GenerateUpload(ctx, params)
+		assert.NoError(t, err)
+		assert.Equal(t, "COMPLIANCE", ret.Header.Get("X-Amz-Object-Lock-Mode"))
+		assert.NotEmpty(t, ret.Header.Get("X-Amz-Object-Lock-Retain-Until-Date"))
+		assert.Equal(t, "ON", ret.Header.Get("X-Amz-Object-Lock-Legal-Hold"))
+	})
+
+	t.Run("POST", func(t *testing.T) {
+		ret, err := newGenerator(false).GenerateUpload(ctx, params)
+		assert.NoError(t, err)
+		assert.Equal(t, "COMPLIANCE", ret.FormData["x-amz-object-lock-mode"])
+
+		policy, err := base64.StdEncoding.DecodeString(ret.FormData["policy"])
+		assert.NoError(t, err)
+		assert.Contains(t, string(policy), `"$x-amz-object-lock-mode","COMPLIANCE"`)
+		assert.Contains(t, string(policy), `"$x-amz-object-lock-legal-hold","ON"`)
+	})
+
+	t.Run("RequireSha256", func(t *testing.T) {
+		_, err := newGenerator(true).GenerateUpload(ctx, &s3up.GenerateParams{
+			RemotePath: "audit.log",
+			ExpireIn:   time.Minute,
+			Size:       5,
+		})
+		assert.ErrorIs(t, err, s3common.ErrInvalidArgument)
+	})
+}
+
+func TestGeneratorS3CORSRequirement(t *testing.T) {
+	newGenerator := func(cfg *s3up.GeneratorS3Config) s3up.CORSGenerator {
+		return newGeneratorS3(t, cfg).(s3up.CORSGenerator)
+	}
+
+	t.Run("Default", func(t *testing.T) {
+		req := newGenerator(&s3up.GeneratorS3Config{}).CORSRequirement()
+		assert.ElementsMatch(t, []string{http.MethodPut, http.MethodPost}, req.Methods)
+		assert.Equal(t, []string{
+			"content-type",
+			"x-amz-checksum-algorithm",
+			"x-amz-checksum-sha256",
+			"x-amz-meta-*",
+		}, req.AllowedHeaders)
+		assert.Contains(t, req.ExposeHeaders, "etag")
+	})
+
+	t.Run("EncryptionRetention", func(t *testing.T) {
+		req := newGenerator(&s3up.GeneratorS3Config{
+			DisablePOST: true,
+			Encryption: &s3common.EncryptionConfig{
+				Type:     s3common.EncryptionTypeKMS,
+				KMSKeyID: "my-key",
+			},
+			Retention: &s3common.RetentionConfig{LegalHold: true},
+		}).CORSRequirement()
+		assert.Equal(t, []string{http.MethodPut}, req.Methods)
+		assert.Contains(t, req.AllowedHeaders, "x-amz-server-side-encryption")
+		assert.Contains(t, req.AllowedHeaders, "x-amz-server-side-encryption-aws-kms-key-id")
+		assert.Contains(t, req.AllowedHeaders, "x-amz-object-lock-legal-hold")
+	})
+
+	t.Run("DisableChecksum", func(t *testing.T) {
+		req := newGenerator(&s3up.GeneratorS3Config{DisableChecksum: true}).CORSRequirement()
+		assert.Equal(t, []string{"content-type", "x-amz-meta-*"}, req.AllowedHeaders)
+	})
+}
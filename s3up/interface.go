@@ -41,3 +41,91 @@ type GenerateResult struct {
 type Generator interface {
 	GenerateUpload(ctx context.Context, params *GenerateParams) (*GenerateResult, error)
 }
+
+type MultipartGenerateParams struct {
+	// required, path of pending file
+	RemotePath string
+
+	// required, pre-signed url expiration of each part
+	ExpireIn time.Duration
+
+	// required, size of pending file
+	Size int64
+
+	// optional, size of each part, default to 16 MiB, at least 5 MiB
+	// required when PartSha256 is set, since checksums are calculated per part
+	PartSize int64
+
+	// optional, content-type of pending file
+	ContentType string
+
+	// optional, attachment filename while downloading
+	AttachmentFilename string
+
+	// optional, sha256 checksum of each part, length must equal to part count
+	PartSha256 [][]byte
+
+	// optional, object metadata
+	Metadata map[string]string
+}
+
+type MultipartGenerateResult struct {
+	UploadID string
+	PartSize int64
+	Parts    []MultipartPartResult
+}
+
+type MultipartPartResult struct {
+	PartNumber int
+	Size       int64
+
+	Method string
+	URL    *url.URL
+	Header http.Header
+}
+
+type MultipartCompleteParams struct {
+	// required, path of pending file
+	RemotePath string
+
+	// required, upload id returned by GenerateMultipartUpload
+	UploadID string
+
+	// required, size of pending file, should equal to MultipartGenerateParams.Size
+	Size int64
+
+	// required, parts reported by client after uploading
+	Parts []MultipartCompletePart
+}
+
+type MultipartCompletePart struct {
+	PartNumber int
+
+	// ETag response header of UploadPart request
+	ETag string
+
+	// optional, sha256 checksum of part, required when checksum is enforced
+	Sha256 []byte
+}
+
+// MultipartGenerator 为终端用户生成预签名的分片上传链接，用于超过 5GB 或需要分片重试的大文件上传
+type MultipartGenerator interface {
+	GenerateMultipartUpload(ctx context.Context, params *MultipartGenerateParams) (*MultipartGenerateResult, error)
+
+	// CompleteMultipartUpload 校验客户端上报的分片 ETag 与校验和后完成上传
+	CompleteMultipartUpload(ctx context.Context, params *MultipartCompleteParams) error
+
+	AbortMultipartUpload(ctx context.Context, remotePath string, uploadID string) error
+}
+
+// CORSRequirement 浏览器直传所需的 bucket CORS 配置，header 名称均为小写
+type CORSRequirement struct {
+	Methods        []string
+	AllowedHeaders []string
+	ExposeHeaders  []string
+}
+
+// CORSGenerator 可根据自身配置给出浏览器直传所需 CORS 配置的 Generator
+type CORSGenerator interface {
+	CORSRequirement() *CORSRequirement
+}